@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/storage"
+	"share-the-meal/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const usage = `usage: admin <command> [flags]
+
+commands:
+  create-superadmin           -username NAME -email EMAIL [-fullname NAME] [-password PASSWORD]
+  reset-password              -email EMAIL [-password PASSWORD]
+  deactivate-user             -email EMAIL
+  recompute-campaign-totals   [-campaign ID]
+  seed-demo                   [-password PASSWORD]
+
+Passwords that are not given as a flag are read from standard input.`
+
+// actor is recorded in the created_by and modified_by columns
+const actor = "admin-cli"
+
+type admin struct {
+	userRepo     *repository.UserRepository
+	roleRepo     *repository.RoleRepository
+	campaignRepo *repository.CampaignRepository
+	donationRepo *repository.DonationRepository
+	sessionRepo  *repository.SessionRepository
+	resetRepo    *repository.PasswordResetRepository
+	txManager    repository.TxManagerInterface
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	utils.InitCurrencies(cfg.DefaultCurrency, cfg.SupportedCurrencies)
+
+	pool, err := storage.ConnectDB(&cfg.DBConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	if err := run(context.Background(), newAdmin(pool), os.Args[1], os.Args[2:]); err != nil {
+		log.Fatalf("%s failed: %v", os.Args[1], err)
+	}
+}
+
+func newAdmin(pool *pgxpool.Pool) *admin {
+	return &admin{
+		userRepo:     repository.NewUserRepository(pool, "public"),
+		roleRepo:     repository.NewRoleRepository(pool, "public"),
+		campaignRepo: repository.NewCampaignRepository(pool, "public"),
+		donationRepo: repository.NewDonationRepository(pool, "public"),
+		sessionRepo:  repository.NewSessionRepository(pool, "public"),
+		resetRepo:    repository.NewPasswordResetRepository(pool, "public"),
+		txManager:    repository.NewTxManager(pool),
+	}
+}
+
+func run(ctx context.Context, a *admin, command string, args []string) error {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	username := flags.String("username", "", "username")
+	email := flags.String("email", "", "email address")
+	fullname := flags.String("fullname", "", "full name")
+	password := flags.String("password", "", "password, read from standard input when empty")
+	campaignID := flags.Int64("campaign", 0, "campaign ID, all campaigns when 0")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch command {
+	case "create-superadmin":
+		if *username == "" || *email == "" {
+			return fmt.Errorf("-username and -email are required")
+		}
+		return a.createSuperadmin(ctx, *username, *email, *fullname, *password)
+	case "reset-password":
+		if *email == "" {
+			return fmt.Errorf("-email is required")
+		}
+		return a.resetPassword(ctx, *email, *password)
+	case "deactivate-user":
+		if *email == "" {
+			return fmt.Errorf("-email is required")
+		}
+		return a.deactivateUser(ctx, *email)
+	case "recompute-campaign-totals":
+		var id *int64
+		if *campaignID > 0 {
+			id = campaignID
+		}
+		return a.recomputeCampaignTotals(ctx, id)
+	case "seed-demo":
+		return a.seedDemo(ctx, *password)
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+}
+
+func (a *admin) createSuperadmin(ctx context.Context, username, email, fullname, password string) error {
+	if exists, err := a.userRepo.CheckEmailExists(email); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("email %s is already registered", email)
+	}
+	if exists, err := a.userRepo.CheckUsernameExists(username); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("username %s is already taken", username)
+	}
+
+	role, err := a.roleRepo.GetRoleByName(models.RoleSuperadmin)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.createUser(ctx, username, email, fullname, password, role.RoleID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created superadmin %s (id %d)\n", user.Username, user.UserID)
+	return nil
+}
+
+func (a *admin) resetPassword(ctx context.Context, email, password string) error {
+	user, err := a.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := a.userRepo.UpdateUserPassword(ctx, user.UserID, hashed); err != nil {
+		return err
+	}
+	if _, err := a.sessionRepo.RevokeUserSessions(ctx, user.UserID, models.SessionRevokedPasswordChange); err != nil {
+		return err
+	}
+	if err := a.resetRepo.InvalidateUserTokens(ctx, user.UserID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Password of %s has been reset\n", user.Username)
+	return nil
+}
+
+func (a *admin) deactivateUser(ctx context.Context, email string) error {
+	user, err := a.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return err
+	}
+	if err := a.userRepo.DeactivateUser(ctx, user.UserID, actor); err != nil {
+		return err
+	}
+	if _, err := a.sessionRepo.RevokeUserSessions(ctx, user.UserID, models.SessionRevokedDeactivated); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deactivated %s (id %d)\n", user.Username, user.UserID)
+	return nil
+}
+
+func (a *admin) recomputeCampaignTotals(ctx context.Context, campaignID *int64) error {
+	fixed, err := a.campaignRepo.RecomputeCurrentAmounts(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Recomputed campaign totals, %d campaign(s) corrected\n", fixed)
+	return nil
+}
+
+// seedDemo creates a few donors, active campaigns and paid donations for
+// local development. It refuses to run twice.
+func (a *admin) seedDemo(ctx context.Context, password string) error {
+	if exists, err := a.userRepo.CheckEmailExists("demo-donor-1@example.com"); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("demo data has already been seeded")
+	}
+
+	role, err := a.roleRepo.GetRoleByName(models.RoleDonor)
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		password = "demo-password"
+	}
+
+	// Everything is created in one transaction, a failed run leaves nothing
+	// behind and can simply be repeated
+	var donors []*models.User
+	var created []*models.Campaigns
+	err = a.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i := 1; i <= 3; i++ {
+			donor, err := a.createUser(
+				ctx,
+				fmt.Sprintf("demo-donor-%d", i),
+				fmt.Sprintf("demo-donor-%d@example.com", i),
+				fmt.Sprintf("Demo Donor %d", i),
+				password,
+				role.RoleID,
+			)
+			if err != nil {
+				return err
+			}
+			donors = append(donors, donor)
+		}
+
+		now := time.Now()
+		campaigns := []struct {
+			title  string
+			target models.Money
+		}{
+			{"School meals for a year", 5_000_000},
+			{"Emergency food parcels", 2_500_000},
+			{"Community kitchen", 1_000_000},
+		}
+		for i, c := range campaigns {
+			campaign := &models.Campaigns{
+				Title:            c.title,
+				Description:      "Demo campaign created by the admin CLI.",
+				ShortDescription: "Demo campaign",
+				Target:           c.target,
+				Currency:         utils.DefaultCurrency(),
+				StartDate:        now.AddDate(0, 0, -7),
+				EndDate:          now.AddDate(0, 1, 0),
+				Status:           models.CampaignStatusActive,
+				CreatedBy:        actor,
+			}
+			if err := a.campaignRepo.CreateCampaign(ctx, campaign); err != nil {
+				return fmt.Errorf("failed to create campaign: %w", err)
+			}
+
+			for j, donor := range donors {
+				amount := models.Money((i + 1) * (j + 1) * 2_500)
+				donation := &models.Donation{
+					UserID:         donor.UserID,
+					CampaignID:     campaign.CampaignID,
+					Amount:         amount,
+					Currency:       campaign.Currency,
+					OriginalAmount: amount,
+					ExchangeRate:   "1",
+					IsAnonymous:    j == len(donors)-1,
+					PaymentStatus:  models.PaymentStatusPaid,
+					PaymentMethod:  "demo",
+				}
+				if err := a.donationRepo.CreateDonation(ctx, donation); err != nil {
+					return fmt.Errorf("failed to create donation: %w", err)
+				}
+			}
+
+			id := campaign.CampaignID
+			if _, err := a.campaignRepo.RecomputeCurrentAmounts(ctx, &id); err != nil {
+				return err
+			}
+			created = append(created, campaign)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, campaign := range created {
+		fmt.Printf("Created campaign %q (id %d)\n", campaign.Title, campaign.CampaignID)
+	}
+	fmt.Printf("Created %d demo donors with password %q\n", len(donors), password)
+	return nil
+}
+
+func (a *admin) createUser(ctx context.Context, username, email, fullname, password string, roleID int64) (*models.User, error) {
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts created by an operator do not go through email verification
+	verifiedAt := time.Now()
+	return a.userRepo.CreateUser(ctx, &models.User{
+		Username:        username,
+		Fullname:        fullname,
+		Email:           email,
+		Password:        hashed,
+		RoleID:          roleID,
+		IsActive:        true,
+		EmailVerifiedAt: &verifiedAt,
+	})
+}
+
+// hashPassword hashes password, prompting for it when it is empty
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if len(password) < 6 {
+		return "", fmt.Errorf("password must be at least 6 characters")
+	}
+
+	return utils.HashPassword(password)
+}
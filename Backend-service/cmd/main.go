@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"share-the-meal/internal/config"
+	"share-the-meal/internal/handlers"
+	"share-the-meal/internal/jobs"
 	"share-the-meal/internal/middleware"
 	"share-the-meal/internal/routes"
+	"share-the-meal/internal/services"
 	"share-the-meal/internal/storage"
 	"share-the-meal/internal/utils"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -25,6 +31,9 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token
 func main() {
+	migrateOnStart := flag.Bool("migrate-on-start", false, "apply pending migrations before starting the server")
+	flag.Parse()
+
 	// Initialize logger
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
@@ -37,6 +46,8 @@ func main() {
 
 	// Initialize JWT utility
 	utils.InitJWTUtil(cfg.JWTSecret)
+	utils.InitCurrencies(cfg.DefaultCurrency, cfg.SupportedCurrencies)
+	utils.InitBlocklist(cfg.MessageBlocklist)
 
 	// Connect to database
 	pool, err := storage.ConnectDB(&cfg.DBConfig)
@@ -45,11 +56,34 @@ func main() {
 	}
 	defer pool.Close()
 
+	// "server migrate ..." manages the schema and exits
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(context.Background(), pool, logger, flag.Args()[1:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	if *migrateOnStart {
+		if err := runMigrate(context.Background(), pool, logger, []string{"up"}); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	}
+
 	err = utils.InitMinIOUtil(&cfg.MinioConfig)
 	if err != nil {
 		log.Fatalf("Failed to initialize MinIO: %v", err)
 	}
 
+	gateway, err := services.NewPaymentGateway(&cfg.PaymentConfig, cfg.IsDevelopment())
+	if err != nil {
+		log.Fatalf("Failed to initialize payment gateway: %v", err)
+	}
+
+	mailer, err := services.NewMailer(&cfg.MailConfig, cfg.IsDevelopment())
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+
 	// Set Gin mode
 	if !cfg.IsDevelopment() {
 		gin.SetMode(gin.ReleaseMode)
@@ -67,8 +101,24 @@ func main() {
 	hub := utils.NewHub()
 	go hub.Run()
 
+	// Start background jobs
+	scheduler := jobs.NewScheduler(logger)
+	scheduler.Register(
+		jobs.NewRecurringDonationJob(handlers.NewRecurringDonationService(pool, hub, gateway), logger),
+		time.Duration(cfg.RecurringPollSeconds)*time.Second,
+	)
+	scheduler.Register(
+		jobs.NewCampaignLifecycleJob(handlers.NewCampaignService(pool), logger),
+		time.Duration(cfg.CampaignPollSeconds)*time.Second,
+	)
+	scheduler.Register(
+		jobs.NewEmailOutboxJob(handlers.NewEmailService(pool, mailer), logger),
+		time.Duration(cfg.EmailPollSeconds)*time.Second,
+	)
+	scheduler.Start(context.Background())
+
 	// Setup routes
-	routes.SetupRoutes(router, pool, logger, hub)
+	routes.SetupRoutes(router, pool, logger, hub, gateway, mailer)
 
 	// Start server
 	serverAddr := fmt.Sprintf(":%s", cfg.ServerPort)
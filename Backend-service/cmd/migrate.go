@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"share-the-meal/internal/migrate"
+	"share-the-meal/migrations"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up [N]          apply all pending migrations, or the next N
+  down N          roll back the last N migrations
+  to VERSION      migrate up or down to VERSION (0 rolls back everything)
+  status          show the applied version and every migration
+  force VERSION   mark VERSION as applied and clear the dirty flag without running SQL`
+
+// runMigrate implements the migrate subcommand
+func runMigrate(ctx context.Context, pool *pgxpool.Pool, logger *zap.Logger, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n\n%s", migrateUsage)
+	}
+
+	migrator, err := migrate.NewMigrator(pool, migrations.FS, logger)
+	if err != nil {
+		return err
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "up":
+		n := 0
+		if len(args) > 0 {
+			if n, err = parseCount(args[0]); err != nil {
+				return err
+			}
+		}
+		applied, err := migrator.Up(ctx, n)
+		fmt.Printf("Applied %d migration(s)\n", applied)
+		return err
+
+	case "down":
+		if len(args) == 0 {
+			return fmt.Errorf("down needs the number of migrations to roll back")
+		}
+		n, err := parseCount(args[0])
+		if err != nil {
+			return err
+		}
+		rolledBack, err := migrator.Down(ctx, n)
+		fmt.Printf("Rolled back %d migration(s)\n", rolledBack)
+		return err
+
+	case "to", "force":
+		if len(args) == 0 {
+			return fmt.Errorf("%s needs a version", command)
+		}
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		if command == "to" {
+			err = migrator.To(ctx, version)
+		} else {
+			err = migrator.Force(ctx, version)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Database is at version %d\n", version)
+		return nil
+
+	case "status":
+		version, dirty, statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Version: %d", version)
+		if dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Println()
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Printf("  %-8s %06d_%s\n", state, s.Version, s.Name)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", command, migrateUsage)
+	}
+}
+
+func parseCount(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid number of migrations %q", arg)
+	}
+	return n, nil
+}
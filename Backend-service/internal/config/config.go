@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
@@ -11,16 +12,31 @@ import (
 
 // Config Holds All Configuration For The Application
 type Config struct {
-	ServerPort   string
-	DatabaseUrl  string
-	JWTSecret    string
-	Environment  string
-	CORSOrigins  string
-	StoragePath  string
-	MaxFileSize  int64
-	AllowedTypes []string
-	DBConfig     DBConfig
-	MinioConfig  MinioConfig
+	ServerPort           string
+	DatabaseUrl          string
+	JWTSecret            string
+	AccessTokenTTLMins   int64
+	RefreshTokenTTLHours int64
+	PasswordResetTTLMins int64
+	PasswordResetURL     string
+	EmailVerification    EmailVerificationConfig
+	Invitation           InvitationConfig
+	Environment          string
+	CORSOrigins          string
+	StoragePath          string
+	MaxFileSize          int64
+	AllowedTypes         []string
+	IdempotencyTTLHours  int64
+	RecurringPollSeconds int64
+	CampaignPollSeconds  int64
+	EmailPollSeconds     int64
+	DefaultCurrency      string
+	SupportedCurrencies  []string
+	MessageBlocklist     []string
+	DBConfig             DBConfig
+	MinioConfig          MinioConfig
+	PaymentConfig        PaymentConfig
+	MailConfig           MailConfig
 }
 
 type DBConfig struct {
@@ -41,6 +57,43 @@ type MinioConfig struct {
 	Region     string
 }
 
+// Policies for accounts that have not verified their email address
+const (
+	UnverifiedAllow          = "allow"
+	UnverifiedBlockDonations = "block_donations"
+	UnverifiedBlockLogin     = "block_login"
+)
+
+type EmailVerificationConfig struct {
+	TTLHours         int64
+	URL              string
+	ResendSeconds    int64
+	UnverifiedPolicy string
+}
+
+type InvitationConfig struct {
+	TTLHours int64
+	URL      string
+	Secret   string
+}
+
+type MailConfig struct {
+	Driver        string
+	From          string
+	OutboxDir     string
+	SMTPHost      string
+	SMTPPort      int64
+	SMTPUsername  string
+	SMTPPassword  string
+	DefaultLocale string
+	MaxAttempts   int64
+}
+
+type PaymentConfig struct {
+	Provider      string
+	WebhookSecret string
+}
+
 // getEnvreturn environment variable value or default if not set
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -104,17 +157,63 @@ func LoadConfig() (*Config, error) {
 		Region:     getEnv("MINIO_REGION", "us-east-1"),
 	}
 
+	paymentConfig := PaymentConfig{
+		Provider:      getEnv("PAYMENT_PROVIDER", ""),
+		WebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+	}
+
+	mailConfig := MailConfig{
+		Driver:        getEnv("MAIL_DRIVER", ""),
+		From:          getEnv("MAIL_FROM", "Share The Meal <no-reply@sharethemeal.org>"),
+		OutboxDir:     getEnv("MAIL_OUTBOX_DIR", "./outbox"),
+		SMTPHost:      getEnv("SMTP_HOST", ""),
+		SMTPPort:      getEnvAsInt64("SMTP_PORT", 587),
+		SMTPUsername:  getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:  getEnv("SMTP_PASSWORD", ""),
+		DefaultLocale: getEnv("MAIL_DEFAULT_LOCALE", "en"),
+		MaxAttempts:   getEnvAsInt64("MAIL_MAX_ATTEMPTS", 5),
+	}
+
+	emailVerification := EmailVerificationConfig{
+		TTLHours:         getEnvAsInt64("EMAIL_VERIFICATION_TTL_HOURS", 48),
+		URL:              getEnv("EMAIL_VERIFICATION_URL", "http://localhost:8080/api/v1/auth-management/verify-email"),
+		ResendSeconds:    getEnvAsInt64("EMAIL_VERIFICATION_RESEND_SECONDS", 60),
+		UnverifiedPolicy: getEnv("UNVERIFIED_EMAIL_POLICY", UnverifiedBlockDonations),
+	}
+
+	jwtSecret := getEnv("JWT_SECRET", "")
+	invitation := InvitationConfig{
+		TTLHours: getEnvAsInt64("INVITATION_TTL_HOURS", 72),
+		URL:      getEnv("INVITATION_URL", "http://localhost:3000/accept-invitation"),
+		Secret:   getEnv("INVITATION_SECRET", jwtSecret),
+	}
+
 	// Load main configuration
 	config := &Config{
-		ServerPort:   getEnv("SERVER_PORT", "8080"),
-		JWTSecret:    getEnv("JWT_SECRET", ""),
-		Environment:  getEnv("ENVIRONMENT", "development"),
-		CORSOrigins:  getEnv("CORS_ORIGINS", "*"),
-		StoragePath:  getEnv("STORAGE_PATH", "./uploads"),
-		MaxFileSize:  getEnvAsInt64("MAX_FILE_SIZE", 5242880),
-		AllowedTypes: getEnvAsStringSlice("ALLOWED_TYPES", defaultAllowedTypes),
-		DBConfig:     dbConfig,
-		MinioConfig:  minioConfig,
+		ServerPort:           getEnv("SERVER_PORT", "8080"),
+		JWTSecret:            jwtSecret,
+		AccessTokenTTLMins:   getEnvAsInt64("ACCESS_TOKEN_TTL_MINUTES", 15),
+		RefreshTokenTTLHours: getEnvAsInt64("REFRESH_TOKEN_TTL_HOURS", 720),
+		PasswordResetTTLMins: getEnvAsInt64("PASSWORD_RESET_TTL_MINUTES", 60),
+		PasswordResetURL:     getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		EmailVerification:    emailVerification,
+		Invitation:           invitation,
+		Environment:          getEnv("ENVIRONMENT", "development"),
+		CORSOrigins:          getEnv("CORS_ORIGINS", "*"),
+		StoragePath:          getEnv("STORAGE_PATH", "./uploads"),
+		MaxFileSize:          getEnvAsInt64("MAX_FILE_SIZE", 5242880),
+		AllowedTypes:         getEnvAsStringSlice("ALLOWED_TYPES", defaultAllowedTypes),
+		IdempotencyTTLHours:  getEnvAsInt64("IDEMPOTENCY_TTL_HOURS", 24),
+		RecurringPollSeconds: getEnvAsInt64("RECURRING_POLL_SECONDS", 60),
+		CampaignPollSeconds:  getEnvAsInt64("CAMPAIGN_POLL_SECONDS", 60),
+		EmailPollSeconds:     getEnvAsInt64("EMAIL_POLL_SECONDS", 30),
+		DefaultCurrency:      getEnv("DEFAULT_CURRENCY", "USD"),
+		SupportedCurrencies:  getEnvAsStringSlice("SUPPORTED_CURRENCIES", []string{"IDR", "USD", "EUR"}),
+		MessageBlocklist:     getEnvAsStringSlice("MESSAGE_BLOCKLIST", nil),
+		DBConfig:             dbConfig,
+		MinioConfig:          minioConfig,
+		PaymentConfig:        paymentConfig,
+		MailConfig:           mailConfig,
 	}
 
 	// Build database URL from individual components
@@ -147,7 +246,7 @@ func validateConfig(config *Config) error {
 		log.Println("Warning: DB_PASSWORD is not set")
 	}
 
-		// Validasi MinIO
+	// Validasi MinIO
 	if config.MinioConfig.Endpoint == "" {
 		log.Println("Warning: MINIO_ENDPOINT is not set")
 	}
@@ -160,7 +259,30 @@ func validateConfig(config *Config) error {
 	if config.MinioConfig.BucketName == "" {
 		log.Println("Warning: MINIO_BUCKET_NAME is not set")
 	}
-	
+	if config.PaymentConfig.WebhookSecret == "" {
+		log.Println("Warning: PAYMENT_WEBHOOK_SECRET is not set, payment webhooks will be rejected")
+	}
+
+	switch config.EmailVerification.UnverifiedPolicy {
+	case UnverifiedAllow, UnverifiedBlockDonations, UnverifiedBlockLogin:
+	default:
+		log.Printf("Warning: unknown UNVERIFIED_EMAIL_POLICY %q, using %s", config.EmailVerification.UnverifiedPolicy, UnverifiedBlockDonations)
+		config.EmailVerification.UnverifiedPolicy = UnverifiedBlockDonations
+	}
+
+	// A ticker with a non-positive interval panics, so refuse to start
+	for _, poll := range []struct {
+		name    string
+		seconds int64
+	}{
+		{"RECURRING_POLL_SECONDS", config.RecurringPollSeconds},
+		{"CAMPAIGN_POLL_SECONDS", config.CampaignPollSeconds},
+		{"EMAIL_POLL_SECONDS", config.EmailPollSeconds},
+	} {
+		if poll.seconds <= 0 {
+			return fmt.Errorf("%s must be a positive number of seconds, got %d", poll.name, poll.seconds)
+		}
+	}
 
 	// You can add more validation as needed
 	return nil
@@ -0,0 +1,14 @@
+package request
+
+import "time"
+
+// AnalyticsFilter is bound from the query string of the CMS analytics
+// endpoints. From and To are inclusive dates; To defaults to today and From
+// to 30 days before To.
+type AnalyticsFilter struct {
+	From       time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To         time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	CampaignID *int64    `form:"campaign_id" binding:"omitempty,gt=0"`
+	Interval   string    `form:"interval" binding:"omitempty,oneof=day week month"`
+	Limit      int       `form:"limit" binding:"omitempty,gt=0,lte=100"`
+}
@@ -12,13 +12,38 @@ type RegisterRequest struct {
 	Fullname    string `json:"fullname" binding:"required"`
 	PhoneNumber string `json:"phone_number,omitempty"`
 	Address     string `json:"address,omitempty"`
-	Role        int64  `json:"role,omitempty"`
 }
 
+// ChangePasswordRequest carries the reset token in the body, since query
+// strings end up in access logs
 type ChangePasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
 	Password string `json:"password" binding:"required,min=6"`
 }
 
 type ForgetPasswordRequest struct {
 	Email string `json:"email" binding:"required,email"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type CreateInvitationRequest struct {
+	Email  string `json:"email" binding:"required,email"`
+	Role   string `json:"role" binding:"required,oneof=recipient superadmin"`
+	Locale string `json:"locale,omitempty"`
+}
+
+type AcceptInvitationRequest struct {
+	Token       string `json:"token" binding:"required"`
+	Username    string `json:"username" binding:"required,min=3,max=50"`
+	Password    string `json:"password" binding:"required,min=6"`
+	Fullname    string `json:"fullname" binding:"required"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Address     string `json:"address,omitempty"`
+}
@@ -1,22 +1,42 @@
 package request
 
-import "time"
+import (
+	"share-the-meal/internal/models"
+	"time"
+)
 
 type CreateCampaignRequest struct {
-	CampaignID int64     `json:"campaign_id"`
-	Title      string    `json:"title"`
-	Content    string    `json:"content"`
-	ImageUrl   string    `json:"image_url,omitempty"`
-	CreatedBy  string    `json:"created_by"`
-	CreatedAt  time.Time `json:"created_at"`
-	ModifiedBy string    `json:"modified_by"`
-	ModifiedAt time.Time `json:"modified_at"`
-	Target     float64   `json:"target_amount"`
-	Current    float64   `json:"current_amount"`
+	CampaignID int64        `json:"campaign_id"`
+	Title      string       `json:"title"`
+	Content    string       `json:"content"`
+	ImageUrl   string       `json:"image_url,omitempty"`
+	CreatedBy  string       `json:"created_by"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ModifiedBy string       `json:"modified_by"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Target     models.Money `json:"target_amount"`
+	Current    models.Money `json:"current_amount"`
+	Currency   string       `json:"currency" form:"currency"`
+
+	ShortDescription string    `json:"short_description" form:"short_description" binding:"max=500"`
+	StartDate        time.Time `json:"start_date" form:"start_date"`
+	EndDate          time.Time `json:"end_date" form:"end_date" binding:"required"`
+	Status           string    `json:"status" form:"status" binding:"omitempty,oneof=draft scheduled active"`
+	RecipientID      *int64    `json:"recipient_id" form:"recipient_id" binding:"omitempty,gt=0"`
 }
 
 type UpdateCampaignRequest struct {
-	Title       string  `form:"title"`
-	Description string  `form:"description"`
-	Target      float64 `form:"target"`
+	Title       string       `form:"title"`
+	Description string       `form:"description"`
+	Target      models.Money `form:"target"`
+	Currency    string       `form:"currency"`
+
+	ShortDescription string    `form:"short_description" binding:"max=500"`
+	StartDate        time.Time `form:"start_date"`
+	EndDate          time.Time `form:"end_date"`
+	RecipientID      *int64    `form:"recipient_id" binding:"omitempty,gt=0"`
+}
+
+type UpdateCampaignStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft scheduled active completed expired archived"`
 }
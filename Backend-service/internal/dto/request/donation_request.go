@@ -1,7 +1,54 @@
 package request
 
+import (
+	"share-the-meal/internal/models"
+	"time"
+)
+
 type DonationRequest struct {
-	CampaignID  int64   `json:"campaign_id" validate:"required"`
-	Amount      float64 `json:"amount" validate:"required,gt=0"`
-	IsAnonymous bool    `json:"is_anonymous"`
+	CampaignID    int64        `json:"campaign_id" binding:"required"`
+	Amount        models.Money `json:"amount" binding:"required,gt=0"`
+	Currency      string       `json:"currency,omitempty"`
+	IsAnonymous   bool         `json:"is_anonymous"`
+	PaymentMethod string       `json:"payment_method,omitempty"`
+
+	Message        string `json:"message,omitempty" binding:"max=500"`
+	DedicationType string `json:"dedication_type,omitempty" binding:"required_with=DedicationName,omitempty,oneof=honor memory"`
+	DedicationName string `json:"dedication_name,omitempty" binding:"required_with=DedicationType,max=255"`
+
+	// RecurringDonationID is set by the scheduler and IdempotencyKey by the
+	// scheduler or from the Idempotency-Key header, never from the body
+	RecurringDonationID *int64 `json:"-"`
+	IdempotencyKey      string `json:"-"`
+}
+
+type RecurringDonationRequest struct {
+	CampaignID    int64        `json:"campaign_id" binding:"required"`
+	Amount        models.Money `json:"amount" binding:"required,gt=0"`
+	Currency      string       `json:"currency,omitempty"`
+	IsAnonymous   bool         `json:"is_anonymous"`
+	PaymentMethod string       `json:"payment_method,omitempty"`
+	Interval      string       `json:"interval" binding:"omitempty,oneof=weekly monthly yearly"`
+	StartDate     *time.Time   `json:"start_date,omitempty"`
+}
+
+type UpdateDonationStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=paid failed expired"`
+}
+
+type UpdateDonationMessageStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+}
+
+// RefundDonationRequest refunds Amount in the currency the donor paid in.
+// A zero amount refunds the rest of the donation.
+type RefundDonationRequest struct {
+	Amount models.Money `json:"amount" binding:"gte=0"`
+	Reason string       `json:"reason" binding:"max=500"`
+}
+
+type SetExchangeRateRequest struct {
+	BaseCurrency  string `json:"base_currency" binding:"required,len=3"`
+	QuoteCurrency string `json:"quote_currency" binding:"required,len=3"`
+	Rate          string `json:"rate" binding:"required"`
 }
@@ -0,0 +1,68 @@
+package response
+
+import "share-the-meal/internal/models"
+
+// AnalyticsRange echoes the resolved filter; To is inclusive
+type AnalyticsRange struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	CampaignID *int64 `json:"campaign_id,omitempty"`
+	Interval   string `json:"interval,omitempty"`
+}
+
+// AnalyticsTotalsResponse holds donations per period, net of refunds, split
+// by campaign currency since amounts in different currencies are not summed
+type AnalyticsTotalsResponse struct {
+	Range   AnalyticsRange        `json:"range"`
+	Periods []PeriodTotalResponse `json:"periods"`
+}
+
+type PeriodTotalResponse struct {
+	Period        string       `json:"period"`
+	Currency      string       `json:"currency"`
+	Amount        models.Money `json:"amount"`
+	DonationCount int64        `json:"donation_count"`
+	DonorCount    int64        `json:"donor_count"`
+}
+
+type DonorActivityResponse struct {
+	Range   AnalyticsRange        `json:"range"`
+	Periods []DonorActivityPeriod `json:"periods"`
+}
+
+type DonorActivityPeriod struct {
+	Period          string `json:"period"`
+	NewDonors       int64  `json:"new_donors"`
+	ReturningDonors int64  `json:"returning_donors"`
+}
+
+type TopCampaignsResponse struct {
+	Range     AnalyticsRange         `json:"range"`
+	Campaigns []models.CampaignTotal `json:"campaigns"`
+}
+
+type DonorConversionResponse struct {
+	Range           AnalyticsRange `json:"range"`
+	RegisteredUsers int64          `json:"registered_users"`
+	Donors          int64          `json:"donors"`
+	ConversionRate  float64        `json:"conversion_rate"`
+}
+
+type RetentionResponse struct {
+	Range   AnalyticsRange    `json:"range"`
+	Cohorts []CohortRetention `json:"cohorts"`
+}
+
+// CohortRetention follows the donors whose first donation was in Cohort.
+// Months[0] is the cohort month itself, so its rate is always 100.
+type CohortRetention struct {
+	Cohort string                 `json:"cohort"`
+	Size   int64                  `json:"size"`
+	Months []CohortMonthRetention `json:"months"`
+}
+
+type CohortMonthRetention struct {
+	MonthOffset   int     `json:"month_offset"`
+	Donors        int64   `json:"donors"`
+	RetentionRate float64 `json:"retention_rate"`
+}
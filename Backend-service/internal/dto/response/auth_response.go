@@ -1,17 +1,45 @@
 package response
 
+import "time"
+
 type SignInResponse struct {
-	UserID   int64  `json:"user_id"`
-	UserName string `json:"username"`
-	Role     string `json:"role"`
-	Token    string `json:"token"`
+	UserID       int64     `json:"user_id"`
+	UserName     string    `json:"username"`
+	Role         string    `json:"role"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
 }
 
 type RegisterResponse struct {
-	UserID   int64  `json:"user_id"`
-	UserName string `json:"username"`
-	Email    string `json:"email"`
-	Fullname string `json:"fullname"`
-	RoleID   int64  `json:"role"`
+	UserID        int64  `json:"user_id"`
+	UserName      string `json:"username"`
+	Email         string `json:"email"`
+	Fullname      string `json:"fullname"`
+	RoleID        int64  `json:"role"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+type SessionResponse struct {
+	ID         int64     `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
 }
 
+type InvitationResponse struct {
+	ID             int64      `json:"id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedUserID *int64     `json:"accepted_user_id,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy      string     `json:"revoked_by,omitempty"`
+	CreatedBy      string     `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -1,11 +1,47 @@
 package response
 
+import (
+	"share-the-meal/internal/models"
+	"time"
+)
+
 type CampaignResponse struct {
-	CampaignID  int64   `json:"campaign_id"`
-	Title       string  `json:"title"`
-	Content     string  `json:"content"`
-	ImageUrl    string  `json:"image_url"`
-	Target      float64 `json:"target_amount"`
-	Current     float64 `json:"current_amount"`
-	Description string  `json:"description"`
+	CampaignID       int64        `json:"campaign_id"`
+	Title            string       `json:"title"`
+	Content          string       `json:"content"`
+	ImageUrl         string       `json:"image_url"`
+	Target           models.Money `json:"target_amount"`
+	Current          models.Money `json:"current_amount"`
+	Currency         string       `json:"currency"`
+	Description      string       `json:"description"`
+	ShortDescription string       `json:"short_description"`
+	StartDate        time.Time    `json:"start_date"`
+	EndDate          time.Time    `json:"end_date"`
+	Status           string       `json:"status"`
+	RecipientID      *int64       `json:"recipient_id,omitempty"`
+}
+
+// CampaignStatsResponse describes a campaign's paid donations, net of refunds.
+// Amounts are in the campaign currency; percentages are rounded to 2 places.
+type CampaignStatsResponse struct {
+	CampaignID             int64                `json:"campaign_id"`
+	Title                  string               `json:"title"`
+	Currency               string               `json:"currency"`
+	TargetAmount           models.Money         `json:"target_amount"`
+	TotalRaised            models.Money         `json:"total_raised"`
+	PercentOfTarget        float64              `json:"percent_of_target"`
+	DonorCount             int64                `json:"donor_count"`
+	DonationCount          int64                `json:"donation_count"`
+	AverageGift            models.Money         `json:"average_gift"`
+	MedianGift             models.Money         `json:"median_gift"`
+	AnonymousDonationCount int64                `json:"anonymous_donation_count"`
+	AnonymousAmount        models.Money         `json:"anonymous_amount"`
+	AnonymousShare         float64              `json:"anonymous_share"`
+	Daily                  []DailyTotalResponse `json:"daily"`
+}
+
+type DailyTotalResponse struct {
+	Date          string       `json:"date"`
+	Amount        models.Money `json:"amount"`
+	DonationCount int64        `json:"donation_count"`
 }
@@ -1,10 +1,78 @@
 package response
 
-import "time"
+import (
+	"share-the-meal/internal/models"
+	"time"
+)
 
 type DonationResponse struct {
-	ID          int64     `json:"id"`
-	Amount      float64   `json:"amount"`
-	IsAnonymous bool      `json:"is_anonymous"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID             int64        `json:"id"`
+	CampaignID     int64        `json:"campaign_id"`
+	Amount         models.Money `json:"amount"`
+	Currency       string       `json:"currency"`
+	OriginalAmount models.Money `json:"original_amount"`
+	ExchangeRate   string       `json:"exchange_rate"`
+	IsAnonymous    bool         `json:"is_anonymous"`
+	PaymentStatus  string       `json:"payment_status"`
+	PaymentMethod  string       `json:"payment_method,omitempty"`
+	TransactionID  string       `json:"transaction_id,omitempty"`
+	CheckoutURL    string       `json:"checkout_url,omitempty"`
+	Message        string       `json:"message,omitempty"`
+	DedicationType string       `json:"dedication_type,omitempty"`
+	DedicationName string       `json:"dedication_name,omitempty"`
+	MessageStatus  string       `json:"message_status,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+type RecurringDonationResponse struct {
+	ID             int64        `json:"id"`
+	CampaignID     int64        `json:"campaign_id"`
+	Amount         models.Money `json:"amount"`
+	Currency       string       `json:"currency"`
+	IsAnonymous    bool         `json:"is_anonymous"`
+	PaymentMethod  string       `json:"payment_method,omitempty"`
+	Interval       string       `json:"interval"`
+	NextChargeDate time.Time    `json:"next_charge_date"`
+	LastChargedAt  *time.Time   `json:"last_charged_at,omitempty"`
+	Status         string       `json:"status"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+type DonationRefundResponse struct {
+	ID              int64        `json:"id"`
+	DonationID      int64        `json:"donation_id"`
+	Amount          models.Money `json:"amount"`
+	OriginalAmount  models.Money `json:"original_amount"`
+	Currency        string       `json:"currency"`
+	Reason          string       `json:"reason,omitempty"`
+	Status          string       `json:"status"`
+	RemainingAmount models.Money `json:"remaining_amount"`
+	CreatedBy       string       `json:"created_by"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+type SupportMessageResponse struct {
+	DonorName      string    `json:"donor_name"`
+	IsAnonymous    bool      `json:"is_anonymous"`
+	Message        string    `json:"message,omitempty"`
+	DedicationType string    `json:"dedication_type,omitempty"`
+	DedicationName string    `json:"dedication_name,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type PublicDonationResponse struct {
+	DonorName   string       `json:"donor_name"`
+	IsAnonymous bool         `json:"is_anonymous"`
+	Amount      models.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type LeaderboardEntryResponse struct {
+	Rank          int          `json:"rank"`
+	DonorName     string       `json:"donor_name"`
+	IsAnonymous   bool         `json:"is_anonymous"`
+	TotalAmount   models.Money `json:"total_amount"`
+	Currency      string       `json:"currency"`
+	DonationCount int          `json:"donation_count"`
 }
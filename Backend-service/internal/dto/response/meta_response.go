@@ -14,6 +14,14 @@ type APIResponse struct {
 	Meta Meta        `json:"meta"`
 }
 
+// PaginatedResponse wraps one page of a list together with the total row count
+type PaginatedResponse struct {
+	Items  interface{} `json:"items"`
+	Total  int64       `json:"total"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+}
+
 func SuccessResponse(data interface{}) APIResponse {
 	return APIResponse{
 		Data: data,
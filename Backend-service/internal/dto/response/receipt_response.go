@@ -0,0 +1,24 @@
+package response
+
+import (
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/utils"
+	"time"
+)
+
+type DonationReceiptResponse struct {
+	ReceiptNumber  string                `json:"receipt_number"`
+	IssuedAt       time.Time             `json:"issued_at"`
+	DonationID     int64                 `json:"donation_id"`
+	DonationDate   time.Time             `json:"donation_date"`
+	DonorName      string                `json:"donor_name"`
+	DonorEmail     string                `json:"donor_email"`
+	CampaignTitle  string                `json:"campaign_title"`
+	Amount         models.Money          `json:"amount"`
+	RefundedAmount models.Money          `json:"refunded_amount"`
+	NetAmount      models.Money          `json:"net_amount"`
+	Currency       string                `json:"currency"`
+	PaymentMethod  string                `json:"payment_method,omitempty"`
+	TransactionID  string                `json:"transaction_id,omitempty"`
+	Company        *utils.CompanyProfile `json:"company"`
+}
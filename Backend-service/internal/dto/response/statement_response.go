@@ -0,0 +1,41 @@
+package response
+
+import (
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/utils"
+	"time"
+)
+
+// DonationStatementResponse summarizes a donor's paid donations for one year.
+// Amounts are in the currency the donor paid in, net of refunds.
+type DonationStatementResponse struct {
+	Year        int                         `json:"year"`
+	DonorName   string                      `json:"donor_name"`
+	DonorEmail  string                      `json:"donor_email"`
+	GeneratedAt time.Time                   `json:"generated_at"`
+	Totals      []StatementTotalResponse    `json:"totals"`
+	Campaigns   []StatementCampaignResponse `json:"campaigns"`
+	Company     *utils.CompanyProfile       `json:"company"`
+}
+
+type StatementTotalResponse struct {
+	Currency      string       `json:"currency"`
+	Amount        models.Money `json:"amount"`
+	DonationCount int          `json:"donation_count"`
+}
+
+type StatementCampaignResponse struct {
+	CampaignID    int64                    `json:"campaign_id"`
+	CampaignTitle string                   `json:"campaign_title"`
+	Currency      string                   `json:"currency"`
+	Amount        models.Money             `json:"amount"`
+	DonationCount int                      `json:"donation_count"`
+	Months        []StatementMonthResponse `json:"months"`
+}
+
+type StatementMonthResponse struct {
+	Month         int          `json:"month"`
+	MonthName     string       `json:"month_name"`
+	Amount        models.Money `json:"amount"`
+	DonationCount int          `json:"donation_count"`
+}
@@ -9,4 +9,5 @@ type UserProfileResponse struct {
 	ProfilePicture string `json:"profile_picture"`
 	PhoneNumber    string `json:"phone_number"`
 	Address        string `json:"address"`
-}
\ No newline at end of file
+	EmailVerified  bool   `json:"email_verified"`
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"share-the-meal/internal/dto/request"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+	logger           *zap.Logger
+}
+
+func NewAnalyticsHandler(db *pgxpool.Pool, logger *zap.Logger) *AnalyticsHandler {
+	analyticsRepo := repository.NewAnalyticsRepository(db, "public")
+
+	return &AnalyticsHandler{
+		analyticsService: services.NewAnalyticsService(analyticsRepo),
+		logger:           logger,
+	}
+}
+
+// serveAnalytics binds the analytics filter, runs query and writes its result
+func serveAnalytics[T any](h *AnalyticsHandler, c *gin.Context, name string, query func(context.Context, request.AnalyticsFilter) (T, error)) {
+	var filter request.AnalyticsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	res, err := query(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAnalyticsRange) {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+			return
+		}
+		h.logger.Error("Failed to get analytics", zap.String("report", name), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get analytics"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(res))
+}
+
+// GetDonationTotals godoc
+// @Summary Get donation totals over time
+// @Description Sum paid donations, net of refunds, per day, week or month and campaign currency (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD), defaults to today"
+// @Param campaign_id query int false "Campaign ID"
+// @Param interval query string false "Period length" Enums(day, week, month) default(day)
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.AnalyticsTotalsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/analytics/totals [get]
+func (h *AnalyticsHandler) GetDonationTotals(c *gin.Context) {
+	serveAnalytics(h, c, "totals", h.analyticsService.GetTotals)
+}
+
+// GetDonorActivity godoc
+// @Summary Get new vs returning donors
+// @Description Count, per period, the donors giving for the first time and those who gave before (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD), defaults to today"
+// @Param campaign_id query int false "Campaign ID"
+// @Param interval query string false "Period length" Enums(day, week, month) default(day)
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.DonorActivityResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/analytics/donors [get]
+func (h *AnalyticsHandler) GetDonorActivity(c *gin.Context) {
+	serveAnalytics(h, c, "donors", h.analyticsService.GetDonorActivity)
+}
+
+// GetTopCampaigns godoc
+// @Summary Get top campaigns
+// @Description Rank campaigns by the amount raised in the date range, in each campaign's currency (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD), defaults to today"
+// @Param limit query int false "Number of campaigns" default(10)
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.TopCampaignsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/analytics/top-campaigns [get]
+func (h *AnalyticsHandler) GetTopCampaigns(c *gin.Context) {
+	serveAnalytics(h, c, "top-campaigns", h.analyticsService.GetTopCampaigns)
+}
+
+// GetDonorConversion godoc
+// @Summary Get user to donor conversion
+// @Description Count the users registered in the date range and how many of them have made a paid donation (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD), defaults to today"
+// @Param campaign_id query int false "Only count donations to this campaign"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.DonorConversionResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/analytics/conversion [get]
+func (h *AnalyticsHandler) GetDonorConversion(c *gin.Context) {
+	serveAnalytics(h, c, "conversion", h.analyticsService.GetDonorConversion)
+}
+
+// GetRetention godoc
+// @Summary Get donor retention cohorts
+// @Description Group donors by the month of their first donation and show how many gave again in each later month (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param from query string false "First cohort date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "Last cohort date, inclusive (YYYY-MM-DD), defaults to today"
+// @Param campaign_id query int false "Campaign ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.RetentionResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/analytics/retention [get]
+func (h *AnalyticsHandler) GetRetention(c *gin.Context) {
+	serveAnalytics(h, c, "retention", h.analyticsService.GetRetention)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	// "context"
+	"errors"
 	"net/http"
 	"share-the-meal/internal/config"
 	"share-the-meal/internal/dto/request"
@@ -9,6 +10,7 @@ import (
 	"share-the-meal/internal/repository"
 	"share-the-meal/internal/services"
 	"share-the-meal/internal/utils"
+	"time"
 
 	// "strconv"
 
@@ -21,21 +23,55 @@ type AuthHandler struct {
 	DB      *pgxpool.Pool
 	Logger  *zap.Logger
 	service *services.AuthService
-	jwtUtil *utils.JWTUtil
 }
 
-func NewAuthHandler(db *pgxpool.Pool, logger *zap.Logger) *AuthHandler {
+func NewAuthHandler(db *pgxpool.Pool, logger *zap.Logger, mailer services.Mailer) *AuthHandler {
 	userRepo := repository.NewUserRepository(db, "public")
 	roleRepo := repository.NewRoleRepository(db, "public")
+	resetRepo := repository.NewPasswordResetRepository(db, "public")
+	verificationRepo := repository.NewEmailVerificationRepository(db, "public")
+	txManager := repository.NewTxManager(db)
 	cfg, _ := config.GetConfig()
-	jwtUtil := utils.NewJWTUtil(cfg.JWTSecret)
-	authService := services.NewAuthService(userRepo, roleRepo, jwtUtil)
+	emailService := NewEmailService(db, mailer)
+	authService := services.NewAuthService(
+		userRepo,
+		roleRepo,
+		resetRepo,
+		txManager,
+		emailService,
+		services.NewEmailVerificationService(userRepo, verificationRepo, txManager, emailService, cfg.EmailVerification),
+		NewSessionService(db),
+		time.Duration(cfg.PasswordResetTTLMins)*time.Minute,
+		cfg.PasswordResetURL,
+	)
 
 	return &AuthHandler{
 		DB:      db,
 		Logger:  logger,
 		service: authService,
-		jwtUtil: jwtUtil,
+	}
+}
+
+// NewSessionService wires the session service, which is shared by the sign-in
+// routes and the device management routes of the user.
+func NewSessionService(db *pgxpool.Pool) *services.SessionService {
+	cfg, _ := config.GetConfig()
+
+	return services.NewSessionService(
+		repository.NewSessionRepository(db, "public"),
+		repository.NewUserRepository(db, "public"),
+		repository.NewRoleRepository(db, "public"),
+		repository.NewTxManager(db),
+		utils.NewJWTUtil(cfg.JWTSecret),
+		time.Duration(cfg.AccessTokenTTLMins)*time.Minute,
+		time.Duration(cfg.RefreshTokenTTLHours)*time.Hour,
+	)
+}
+
+func sessionClient(c *gin.Context) services.SessionClient {
+	return services.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
 	}
 }
 
@@ -53,7 +89,7 @@ func (h *AuthHandler) SignInUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Login(req.Email, req.Password)
+	user, err := h.service.Login(c.Request.Context(), req.Email, req.Password, sessionClient(c))
 	if err != nil {
 		var statusCode int
 		var errorMessage string
@@ -64,6 +100,9 @@ func (h *AuthHandler) SignInUser(c *gin.Context) {
 		} else if err.Error() == "password salah" {
 			statusCode = http.StatusUnauthorized
 			errorMessage = "Incorrect password"
+		} else if errors.Is(err, services.ErrEmailNotVerified) {
+			statusCode = http.StatusForbidden
+			errorMessage = "Please verify your email address before signing in"
 		} else {
 			statusCode = http.StatusInternalServerError
 			errorMessage = "Internal server error"
@@ -105,7 +144,7 @@ func (h *AuthHandler) RegisterUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Register(req)
+	user, err := h.service.Register(c.Request.Context(), req, c.GetHeader("Accept-Language"))
 	if err != nil {
 		var statusCode int
 		var errorMessage string
@@ -142,11 +181,49 @@ func (h *AuthHandler) RegisterUser(c *gin.Context) {
 	c.JSON(http.StatusCreated, apiResponse)
 }
 
-// POST /api/v1/auth-management/forgot-password
-func (h *AuthHandler) ForgetPassword(c *gin.Context) {
-	var req request.ForgetPasswordRequest
+// GET /api/v1/auth-management/verify-email
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, response.Meta{
+			Code:    http.StatusBadRequest,
+			Message: "Token is required",
+			Status:  "error",
+		})
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), token); err != nil {
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, response.Meta{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid or expired verification link",
+				Status:  "error",
+			})
+			return
+		}
+
+		h.Logger.Error("Failed to verify email", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Meta{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+			Status:  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Meta{
+		Code:    http.StatusOK,
+		Message: "Email verified successfully",
+		Status:  "success",
+	})
+}
+
+// POST /api/v1/auth-management/resend-verification
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req request.ResendVerificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.Logger.Error("Forget password validation failed", zap.Error(err))
+		h.Logger.Error("Validation failed", zap.Error(err))
 		c.JSON(http.StatusBadRequest, response.Meta{
 			Code:    http.StatusBadRequest,
 			Message: "Invalid request body",
@@ -155,20 +232,25 @@ func (h *AuthHandler) ForgetPassword(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.ForgetPassword(req.Email)
+	err := h.service.ResendVerification(c.Request.Context(), req.Email, c.GetHeader("Accept-Language"))
 	if err != nil {
-		var statusCode int
-		var errorMessage string
+		statusCode := http.StatusInternalServerError
+		errorMessage := "Internal server error"
 
-		if err.Error() == "email not found" {
+		switch {
+		case err.Error() == "email not found":
 			statusCode = http.StatusNotFound
 			errorMessage = "Email not found"
-		} else {
-			statusCode = http.StatusInternalServerError
-			errorMessage = "Internal server error"
+		case errors.Is(err, services.ErrEmailAlreadyVerified):
+			statusCode = http.StatusConflict
+			errorMessage = "Email is already verified"
+		case errors.Is(err, services.ErrVerificationThrottled):
+			statusCode = http.StatusTooManyRequests
+			errorMessage = err.Error()
+		default:
+			h.Logger.Error("Failed to resend verification email", zap.Error(err))
 		}
 
-		h.Logger.Error("Failed to process forget password request", zap.Error(err))
 		c.JSON(statusCode, response.Meta{
 			Code:    statusCode,
 			Message: errorMessage,
@@ -177,23 +259,64 @@ func (h *AuthHandler) ForgetPassword(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, response.Meta{
+		Code:    http.StatusOK,
+		Message: "Verification email sent",
+		Status:  "success",
+	})
 }
 
-// PUT /api/v1/auth-management/change-password
-func (h *AuthHandler) ChangePassword(c *gin.Context) {
-	token := c.Query("token")
-	if token == "" {
-		h.Logger.Error("Token is required")
+// POST /api/v1/auth-management/refresh
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req request.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Logger.Error("Validation failed", zap.Error(err))
 		c.JSON(http.StatusBadRequest, response.Meta{
 			Code:    http.StatusBadRequest,
-			Message: "Token is required",
+			Message: "Invalid request body",
 			Status:  "error",
 		})
 		return
 	}
 
-	var req request.ChangePasswordRequest
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken, sessionClient(c))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorMessage := "Internal server error"
+
+		switch {
+		case errors.Is(err, services.ErrRefreshTokenReused):
+			statusCode = http.StatusUnauthorized
+			errorMessage = "Refresh token reuse detected, please sign in again"
+			h.Logger.Warn("Refresh token reuse detected", zap.String("ip", c.ClientIP()))
+		case errors.Is(err, services.ErrInvalidRefreshToken):
+			statusCode = http.StatusUnauthorized
+			errorMessage = "Invalid or expired refresh token"
+		default:
+			h.Logger.Error("Failed to refresh token", zap.Error(err))
+		}
+
+		c.JSON(statusCode, response.Meta{
+			Code:    statusCode,
+			Message: errorMessage,
+			Status:  http.StatusText(statusCode),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.APIResponse{
+		Data: tokens,
+		Meta: response.Meta{
+			Code:    http.StatusOK,
+			Message: "Token refreshed",
+			Status:  http.StatusText(http.StatusOK),
+		},
+	})
+}
+
+// POST /api/v1/auth-management/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req request.RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.Logger.Error("Validation failed", zap.Error(err))
 		c.JSON(http.StatusBadRequest, response.Meta{
@@ -204,32 +327,85 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	claims, err := h.jwtUtil.VerifyToken(token)
-	if err != nil {
-		h.Logger.Error("Invalid token", zap.Error(err))
-		c.JSON(http.StatusUnauthorized, response.Meta{
-			Code:    http.StatusUnauthorized,
-			Message: "Invalid or expired token",
+	if err := h.service.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		h.Logger.Error("Failed to log out", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Meta{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
 			Status:  "error",
 		})
 		return
 	}
 
-	userIDFloat, ok := claims["id"].(float64)
-	if !ok {
-		h.Logger.Error("Invalid user ID in token")
+	c.JSON(http.StatusOK, response.Meta{
+		Code:    http.StatusOK,
+		Message: "Signed out successfully",
+		Status:  "success",
+	})
+}
+
+// POST /api/v1/auth-management/forgot-password
+func (h *AuthHandler) ForgetPassword(c *gin.Context) {
+	var req request.ForgetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Logger.Error("Forget password validation failed", zap.Error(err))
 		c.JSON(http.StatusBadRequest, response.Meta{
 			Code:    http.StatusBadRequest,
-			Message: "Invalid user ID in token",
+			Message: "Invalid request body",
 			Status:  "error",
 		})
 		return
 	}
 
-	userID := int64(userIDFloat)
+	result, err := h.service.ForgetPassword(c.Request.Context(), req.Email, c.GetHeader("Accept-Language"))
+	if err != nil {
+		var statusCode int
+		var errorMessage string
+
+		if err.Error() == "email not found" {
+			statusCode = http.StatusNotFound
+			errorMessage = "Email not found"
+		} else {
+			statusCode = http.StatusInternalServerError
+			errorMessage = "Internal server error"
+		}
+
+		h.Logger.Error("Failed to process forget password request", zap.Error(err))
+		c.JSON(statusCode, response.Meta{
+			Code:    statusCode,
+			Message: errorMessage,
+			Status:  http.StatusText(statusCode),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PUT /api/v1/auth-management/change-password
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req request.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.Meta{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request body",
+			Status:  "error",
+		})
+		return
+	}
 
-	err = h.service.ChangePassword(userID, req.Password)
+	err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			c.JSON(http.StatusUnauthorized, response.Meta{
+				Code:    http.StatusUnauthorized,
+				Message: "Invalid or expired token",
+				Status:  "error",
+			})
+			return
+		}
+
 		h.Logger.Error("Failed to change password", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.Meta{
 			Code:    http.StatusInternalServerError,
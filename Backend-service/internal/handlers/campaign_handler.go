@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"share-the-meal/internal/dto/response"
 	"share-the-meal/internal/repository"
@@ -30,15 +31,22 @@ type CampaignHandler struct {
 
 // Ganti inisialisasi
 func NewCampaignHandler(db *pgxpool.Pool, logger *zap.Logger) *CampaignHandler {
-    campaignRepo := repository.NewCampaignRepository(db, "public")
-    minioUtil := utils.GetMinIOUtil()
-    campaignService := services.NewCampaignService(campaignRepo, minioUtil)
+    campaignService := NewCampaignService(db)
     return &CampaignHandler{
         campaignService: campaignService,
         logger:          logger,
     }
 }
 
+// NewCampaignService wires the campaign service, which is shared by the HTTP
+// handlers and the campaign lifecycle job.
+func NewCampaignService(db *pgxpool.Pool) *services.CampaignService {
+	campaignRepo := repository.NewCampaignRepository(db, "public")
+	minioUtil := utils.GetMinIOUtil()
+
+	return services.NewCampaignService(campaignRepo, minioUtil)
+}
+
 func (h *CampaignHandler) ListActiveCampaigns(c *gin.Context) {
 	campaigns, err := h.campaignService.ListActiveCampaigns(c.Request.Context())
 	if err != nil {
@@ -60,6 +68,10 @@ func (h *CampaignHandler) GetCampaignDetails(c *gin.Context) {
 
 	campaign, err := h.campaignService.GetCampaignDetails(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, repository.ErrCampaignNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+			return
+		}
 		h.logger.Error("Failed to get campaign details", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get campaign details"))
 		return
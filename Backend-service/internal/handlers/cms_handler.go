@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"mime/multipart"
 	"net/http"
 	"share-the-meal/internal/dto/request"
 	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/models"
 	"share-the-meal/internal/repository"
 	"share-the-meal/internal/utils"
 
@@ -18,20 +21,28 @@ import (
 )
 
 type CMSHandler struct {
-	campaignService *services.CampaignService
-	donationService *services.DonationService
-	logger          *zap.Logger
+	campaignService     *services.CampaignService
+	donationService     *services.DonationService
+	exchangeRateService *services.ExchangeRateService
+	statementService    *services.StatementService
+	logger              *zap.Logger
 }
 
-func NewCMSHandler(db *pgxpool.Pool, logger *zap.Logger) *CMSHandler {
+func NewCMSHandler(db *pgxpool.Pool, logger *zap.Logger, hub *utils.Hub, gateway services.PaymentGateway) *CMSHandler {
 	campaignRepo := repository.NewCampaignRepository(db, "public")
 	donationRepo := repository.NewDonationRepository(db, "public")
+	refundRepo := repository.NewDonationRefundRepository(db, "public")
+	notificationRepo := repository.NewNotificationsRepository(db, "public")
+	exchangeRateService := services.NewExchangeRateService(repository.NewExchangeRateRepository(db, "public"))
+	userRepo := repository.NewUserRepository(db, "public")
 	minioUtil := utils.GetMinIOUtil()
 
 	return &CMSHandler{
-		campaignService: services.NewCampaignService(campaignRepo, minioUtil),
-		donationService: services.NewDonationService(donationRepo, campaignRepo, nil, nil),
-		logger:          logger,
+		campaignService:     services.NewCampaignService(campaignRepo, minioUtil),
+		donationService:     services.NewDonationService(donationRepo, refundRepo, campaignRepo, notificationRepo, repository.NewTxManager(db), gateway, exchangeRateService, hub),
+		exchangeRateService: exchangeRateService,
+		statementService:    services.NewStatementService(donationRepo, refundRepo, campaignRepo, userRepo),
+		logger:              logger,
 	}
 }
 
@@ -44,6 +55,12 @@ func NewCMSHandler(db *pgxpool.Pool, logger *zap.Logger) *CMSHandler {
 // @Param title formData string true "Campaign title"
 // @Param description formData string true "Campaign description"
 // @Param target formData number true "Target amount"
+// @Param currency formData string false "ISO 4217 currency code"
+// @Param short_description formData string false "Short description"
+// @Param start_date formData string false "Start date (RFC 3339), defaults to now"
+// @Param end_date formData string true "End date (RFC 3339)"
+// @Param status formData string false "Initial status, scheduled and active publish the campaign according to its dates" Enums(draft, scheduled, active) default(draft)
+// @Param recipient_id formData int false "Recipient user ID"
 // @Param image formData file true "Campaign image"
 // @Security BearerAuth
 // @Success 201 {object} response.APIResponse{data=response.CampaignResponse}
@@ -65,9 +82,15 @@ func (h *CMSHandler) CreateCampaign(c *gin.Context) {
 		return
 	}
 
-	campaign, err := h.campaignService.CreateCampaign(c.Request.Context(), req, file)
+	campaign, err := h.campaignService.CreateCampaign(c.Request.Context(), req, file, c.GetString("username"))
 	if err != nil {
 		h.logger.Error("Failed to create campaign", zap.Error(err))
+		if errors.Is(err, services.ErrUnsupportedCurrency) ||
+			errors.Is(err, services.ErrInvalidCampaignDates) ||
+			errors.Is(err, services.ErrCampaignEnded) {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to create campaign"))
 		return
 	}
@@ -77,12 +100,12 @@ func (h *CMSHandler) CreateCampaign(c *gin.Context) {
 
 // GetCampaignStats godoc
 // @Summary Get campaign statistics
-// @Description Get statistics for a campaign (Superadmin only)
+// @Description Get totals, donor counts, average and median gift, progress towards the target, a daily series and the anonymous share for a campaign, net of refunds (Superadmin only)
 // @Tags CMS
 // @Produce json
 // @Param id path int true "Campaign ID"
 // @Security BearerAuth
-// @Success 200 {object} response.APIResponse{data=response.CampaignResponse}
+// @Success 200 {object} response.APIResponse{data=response.CampaignStatsResponse}
 // @Failure 400 {object} response.APIResponse
 // @Failure 401 {object} response.APIResponse "Unauthorized"
 // @Failure 403 {object} response.APIResponse "Forbidden"
@@ -98,6 +121,10 @@ func (h *CMSHandler) GetCampaignStats(c *gin.Context) {
 
 	stats, err := h.donationService.GetCampaignStats(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, repository.ErrCampaignNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+			return
+		}
 		h.logger.Error("Failed to get stats", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get stats"))
 		return
@@ -116,10 +143,16 @@ func (h *CMSHandler) GetCampaignStats(c *gin.Context) {
 // @Param title formData string false "Campaign title"
 // @Param description formData string false "Campaign description"
 // @Param target formData number false "Target amount"
+// @Param currency formData string false "ISO 4217 currency code, only while nothing has been raised"
+// @Param short_description formData string false "Short description"
+// @Param start_date formData string false "Start date (RFC 3339)"
+// @Param end_date formData string false "End date (RFC 3339)"
+// @Param recipient_id formData int false "Recipient user ID"
 // @Param image formData file false "Campaign image"
 // @Security BearerAuth
 // @Success 200 {object} response.APIResponse{data=response.CampaignResponse}
 // @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /cms/campaigns/{id} [put]
 func (h *CMSHandler) UpdateCampaign(c *gin.Context) {
@@ -142,9 +175,19 @@ func (h *CMSHandler) UpdateCampaign(c *gin.Context) {
 		file = formFile
 	}
 
-	campaign, err := h.campaignService.UpdateCampaign(c.Request.Context(), id, req, file)
+	campaign, err := h.campaignService.UpdateCampaign(c.Request.Context(), id, req, file, c.GetString("username"))
 	if err != nil {
 		h.logger.Error("Failed to update campaign", zap.Error(err))
+		if errors.Is(err, services.ErrUnsupportedCurrency) ||
+			errors.Is(err, services.ErrCurrencyLocked) ||
+			errors.Is(err, services.ErrInvalidCampaignDates) {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+			return
+		}
+		if errors.Is(err, repository.ErrCampaignNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to update campaign"))
 		return
 	}
@@ -152,6 +195,83 @@ func (h *CMSHandler) UpdateCampaign(c *gin.Context) {
 	c.JSON(http.StatusOK, response.SuccessResponse(campaign))
 }
 
+// ListCampaigns godoc
+// @Summary List campaigns
+// @Description List campaigns in every status, newest first (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param status query string false "Only campaigns in this status" Enums(draft, pending_review, scheduled, active, completed, expired, archived)
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=[]response.CampaignResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/campaigns [get]
+func (h *CMSHandler) ListCampaigns(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.CampaignStatusDraft, models.CampaignStatusPendingReview, models.CampaignStatusScheduled, models.CampaignStatusActive,
+		models.CampaignStatusCompleted, models.CampaignStatusExpired, models.CampaignStatusArchived:
+	default:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid status"))
+		return
+	}
+
+	campaigns, err := h.campaignService.ListCampaigns(c.Request.Context(), status)
+	if err != nil {
+		h.logger.Error("Failed to list campaigns", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list campaigns"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaigns))
+}
+
+// UpdateCampaignStatus godoc
+// @Summary Update campaign status
+// @Description Move a campaign through its lifecycle. Scheduled and active publish the campaign, which also approves a campaign pending review: it is scheduled until its start date and active until its end date. Draft sends a campaign under review back to its recipient (Superadmin only)
+// @Tags CMS
+// @Accept json
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param request body request.UpdateCampaignStatusRequest true "New status"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.CampaignResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/campaigns/{id}/status [put]
+func (h *CMSHandler) UpdateCampaignStatus(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid campaign ID"))
+		return
+	}
+
+	var req request.UpdateCampaignStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	campaign, err := h.campaignService.UpdateCampaignStatus(c.Request.Context(), id, req.Status, c.GetString("username"))
+	if err != nil {
+		h.logger.Error("Failed to update campaign status", zap.Int64("campaign_id", id), zap.Error(err))
+		switch {
+		case errors.Is(err, repository.ErrCampaignNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+		case errors.Is(err, services.ErrInvalidCampaignStatusTransition), errors.Is(err, services.ErrCampaignEnded):
+			c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to update campaign status"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaign))
+}
+
 // DeleteCampaign godoc
 // @Summary Delete a campaign
 // @Description Delete a campaign (Superadmin only)
@@ -200,3 +320,335 @@ func (h *CMSHandler) ListAllDonations(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.SuccessResponse(donations))
 }
+
+// UpdateDonationStatus godoc
+// @Summary Update donation payment status
+// @Description Move a donation through its payment lifecycle (Superadmin only)
+// @Tags CMS
+// @Accept json
+// @Produce json
+// @Param id path int true "Donation ID"
+// @Param status body request.UpdateDonationStatusRequest true "New payment status"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.DonationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/donations/{id}/status [put]
+func (h *CMSHandler) UpdateDonationStatus(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid donation ID"))
+		return
+	}
+
+	var req request.UpdateDonationStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	donation, err := h.donationService.UpdatePaymentStatus(c.Request.Context(), id, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDonationNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Donation not found"))
+		case errors.Is(err, services.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+		default:
+			h.logger.Error("Failed to update donation status", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to update donation status"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(donation))
+}
+
+// UpdateDonationMessageStatus godoc
+// @Summary Moderate a donor message
+// @Description Approve or reject the message and dedication left on a donation. Only approved messages appear on the wall of support (Superadmin only)
+// @Tags CMS
+// @Accept json
+// @Produce json
+// @Param id path int true "Donation ID"
+// @Param status body request.UpdateDonationMessageStatusRequest true "Moderation status"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.DonationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/donations/{id}/message-status [put]
+func (h *CMSHandler) UpdateDonationMessageStatus(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid donation ID"))
+		return
+	}
+
+	var req request.UpdateDonationMessageStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	donation, err := h.donationService.UpdateMessageStatus(c.Request.Context(), id, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDonationNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Donation not found"))
+		case errors.Is(err, services.ErrDonationHasNoMessage):
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		default:
+			h.logger.Error("Failed to update donation message status", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to update message status"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(donation))
+}
+
+// RefundDonation godoc
+// @Summary Refund a donation
+// @Description Refund part or all of a paid donation. The amount is in the currency the donor paid in; omit it to refund the rest of the donation. The donation is kept as is and a refund entry is recorded (Superadmin only)
+// @Tags CMS
+// @Accept json
+// @Produce json
+// @Param id path int true "Donation ID"
+// @Param refund body request.RefundDonationRequest true "Refund"
+// @Security BearerAuth
+// @Success 201 {object} response.APIResponse{data=response.DonationRefundResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/donations/{id}/refund [post]
+func (h *CMSHandler) RefundDonation(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid donation ID"))
+		return
+	}
+
+	var req request.RefundDonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	refund, err := h.donationService.RefundDonation(c.Request.Context(), id, req, c.GetString("username"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDonationNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Donation not found"))
+		case errors.Is(err, services.ErrRefundExceedsDonation):
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		case errors.Is(err, services.ErrDonationNotRefundable):
+			c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+		default:
+			h.logger.Error("Failed to refund donation", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to refund donation"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse(refund))
+}
+
+// ListDonationRefunds godoc
+// @Summary List donation refunds
+// @Description List the refunds recorded against a donation, oldest first (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param id path int true "Donation ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=[]response.DonationRefundResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/donations/{id}/refunds [get]
+func (h *CMSHandler) ListDonationRefunds(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid donation ID"))
+		return
+	}
+
+	refunds, err := h.donationService.GetDonationRefunds(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrDonationNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Donation not found"))
+			return
+		}
+		h.logger.Error("Failed to list donation refunds", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list donation refunds"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(refunds))
+}
+
+// GenerateDonationStatements godoc
+// @Summary Bulk-generate annual giving statements
+// @Description Render the PDF statement of every donor with a paid donation in the year and download them as a ZIP archive (Superadmin only)
+// @Tags CMS
+// @Produce application/zip
+// @Param year query int false "Statement year" default(current year)
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/donations/statements [post]
+func (h *CMSHandler) GenerateDonationStatements(c *gin.Context) {
+	year, ok := parseStatementYear(c)
+	if !ok {
+		return
+	}
+
+	// The archive is streamed, so the statement count can only follow as a trailer
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="statements-%d.zip"`, year))
+	c.Header("Trailer", "X-Statement-Count")
+
+	count, err := h.statementService.GenerateYearStatements(c.Request.Context(), year, c.Writer)
+	if err != nil {
+		h.logger.Error("Failed to generate donation statements", zap.Int("year", year), zap.Error(err))
+		if c.Writer.Written() {
+			// Part of the archive has been sent. The central directory is
+			// written last, so the cut-off ZIP fails to open on the client.
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Trailer")
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to generate statements"))
+		return
+	}
+
+	h.logger.Info("Generated donation statements", zap.Int("year", year), zap.Int("count", count))
+	c.Writer.Header().Set("X-Statement-Count", strconv.Itoa(count))
+}
+
+// SyncDonationPayment godoc
+// @Summary Sync donation payment status
+// @Description Refresh a donation's payment status from the payment gateway (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param id path int true "Donation ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.DonationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/donations/{id}/sync-payment [post]
+func (h *CMSHandler) SyncDonationPayment(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid donation ID"))
+		return
+	}
+
+	donation, err := h.donationService.SyncPaymentStatus(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDonationNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Donation not found"))
+		case errors.Is(err, services.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+		default:
+			h.logger.Error("Failed to sync donation payment", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to sync donation payment"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(donation))
+}
+
+// ListExchangeRates godoc
+// @Summary List exchange rates
+// @Description List the stored currency exchange rates (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=[]models.ExchangeRate}
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/exchange-rates [get]
+func (h *CMSHandler) ListExchangeRates(c *gin.Context) {
+	rates, err := h.exchangeRateService.ListRates(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list exchange rates", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list exchange rates"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(rates))
+}
+
+// SetExchangeRate godoc
+// @Summary Create or update an exchange rate
+// @Description One unit of base_currency equals rate units of quote_currency (Superadmin only)
+// @Tags CMS
+// @Accept json
+// @Produce json
+// @Param rate body request.SetExchangeRateRequest true "Exchange rate"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=models.ExchangeRate}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/exchange-rates [put]
+func (h *CMSHandler) SetExchangeRate(c *gin.Context) {
+	var req request.SetExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	rate, err := h.exchangeRateService.SetRate(c.Request.Context(), req, c.GetString("username"))
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedCurrency) || errors.Is(err, services.ErrInvalidExchangeRate) {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+			return
+		}
+		h.logger.Error("Failed to set exchange rate", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to set exchange rate"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(rate))
+}
+
+// DeleteExchangeRate godoc
+// @Summary Delete an exchange rate
+// @Description Delete the rate for a currency pair (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param base path string true "Base currency"
+// @Param quote path string true "Quote currency"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/exchange-rates/{base}/{quote} [delete]
+func (h *CMSHandler) DeleteExchangeRate(c *gin.Context) {
+	err := h.exchangeRateService.DeleteRate(c.Request.Context(), c.Param("base"), c.Param("quote"))
+	if err != nil {
+		h.logger.Error("Failed to delete exchange rate", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to delete exchange rate"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("Exchange rate deleted successfully"))
+}
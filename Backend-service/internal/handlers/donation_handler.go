@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	// "strconv"
 	"share-the-meal/internal/dto/request"
 	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/middleware"
 	"share-the-meal/internal/repository"
 	"share-the-meal/internal/services"
 	"share-the-meal/internal/utils"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,27 +19,36 @@ import (
 )
 
 type DonationHandler struct {
-	donationService *services.DonationService
-	logger          *zap.Logger
+	donationService  *services.DonationService
+	receiptService   *services.ReceiptService
+	statementService *services.StatementService
+	logger           *zap.Logger
 }
 
-func NewDonationHandler(db *pgxpool.Pool, logger *zap.Logger) *DonationHandler {
+func NewDonationHandler(db *pgxpool.Pool, logger *zap.Logger, hub *utils.Hub, gateway services.PaymentGateway) *DonationHandler {
 	donationRepo := repository.NewDonationRepository(db, "public")
+	refundRepo := repository.NewDonationRefundRepository(db, "public")
 	campaignRepo := repository.NewCampaignRepository(db, "public")
 	notificationRepo := repository.NewNotificationsRepository(db, "public")
-
-	// Inisialisasi hub (bisa dari tempat lain atau buat baru jika perlu)
-	hub := utils.NewHub()
-	go hub.Run()
+	rateRepo := repository.NewExchangeRateRepository(db, "public")
+	receiptRepo := repository.NewDonationReceiptRepository(db, "public")
+	userRepo := repository.NewUserRepository(db, "public")
+	txManager := repository.NewTxManager(db)
 
 	return &DonationHandler{
 		donationService: services.NewDonationService(
 			donationRepo,
+			refundRepo,
 			campaignRepo,
 			notificationRepo,
+			txManager,
+			gateway,
+			services.NewExchangeRateService(rateRepo),
 			hub,
 		),
-		logger: logger,
+		receiptService:   services.NewReceiptService(receiptRepo, donationRepo, refundRepo, campaignRepo, userRepo, txManager),
+		statementService: services.NewStatementService(donationRepo, refundRepo, campaignRepo, userRepo),
+		logger:           logger,
 	}
 }
 
@@ -54,10 +67,27 @@ func (h *DonationHandler) CreateDonation(c *gin.Context) {
 		return
 	}
 
+	// A retry after the key was released must not charge the donor twice, so
+	// the client's key is passed on to the payment provider, scoped to the user
+	if key := c.GetHeader(middleware.IdempotencyKeyHeader); key != "" {
+		req.IdempotencyKey = fmt.Sprintf("donation-%d-%s", userID.(int64), key)
+	}
+
 	donation, err := h.donationService.CreateDonation(c.Request.Context(), req, userID.(int64))
 	if err != nil {
 		h.logger.Error("Failed to create donation", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to create donation"))
+		switch {
+		case errors.Is(err, services.ErrUnsupportedCurrency),
+			errors.Is(err, services.ErrExchangeRateNotFound),
+			errors.Is(err, services.ErrAmountTooSmall),
+			errors.Is(err, services.ErrAmountTooLarge),
+			errors.Is(err, services.ErrCampaignNotActive):
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		case errors.Is(err, repository.ErrCampaignNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+		default:
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to create donation"))
+		}
 		return
 	}
 
@@ -83,12 +113,297 @@ func (h *DonationHandler) GetUserDonations(c *gin.Context) {
 	var res []response.DonationResponse
 	for _, d := range donations {
 		res = append(res, response.DonationResponse{
-			ID:          d.ID,
-			Amount:      d.Amount,
-			IsAnonymous: d.IsAnonymous,
-			CreatedAt:   d.CreatedAt,
+			ID:             d.ID,
+			CampaignID:     d.CampaignID,
+			Amount:         d.Amount,
+			Currency:       d.Currency,
+			OriginalAmount: d.OriginalAmount,
+			ExchangeRate:   d.ExchangeRate,
+			IsAnonymous:    d.IsAnonymous,
+			PaymentStatus:  d.PaymentStatus,
+			PaymentMethod:  d.PaymentMethod,
+			Message:        d.Message,
+			DedicationType: d.DedicationType,
+			DedicationName: d.DedicationName,
+			MessageStatus:  d.MessageStatus,
+			CreatedAt:      d.CreatedAt,
 		})
 	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse(res))
 }
+
+// GetDonationReceipt godoc
+// @Summary Get a donation receipt
+// @Description Render the receipt of one of the current user's paid donations as HTML or PDF. The receipt number is assigned on first request and is sequential per year.
+// @Tags Donations
+// @Produce html
+// @Produce application/pdf
+// @Param id path int true "Donation ID"
+// @Param format query string false "html or pdf" default(html)
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /donations/{id}/receipt [get]
+func (h *DonationHandler) GetDonationReceipt(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid donation ID"))
+		return
+	}
+
+	format := c.DefaultQuery("format", "html")
+	if format != "html" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid format"))
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	receipt, err := h.receiptService.GetDonationReceipt(c.Request.Context(), id, userID.(int64))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDonationNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Donation not found"))
+		case errors.Is(err, services.ErrReceiptUnavailable):
+			c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+		default:
+			h.logger.Error("Failed to get donation receipt", zap.Int64("donation_id", id), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get receipt"))
+		}
+		return
+	}
+
+	if format == "html" {
+		c.HTML(http.StatusOK, "donation_receipt.html", receipt)
+		return
+	}
+
+	pdf, err := h.receiptService.RenderReceiptPDF(receipt)
+	if err != nil {
+		h.logger.Error("Failed to render receipt PDF", zap.Int64("donation_id", id), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to render receipt"))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="receipt-%s.pdf"`, receipt.ReceiptNumber))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// GetDonationStatement godoc
+// @Summary Get the annual giving statement
+// @Description Aggregate the current user's paid donations of a year by campaign and month, net of refunds, as JSON, CSV or PDF
+// @Tags Donations
+// @Produce json
+// @Produce text/csv
+// @Produce application/pdf
+// @Param year query int false "Statement year" default(current year)
+// @Param format query string false "json, csv or pdf" default(json)
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.DonationStatementResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /users/donations/statement [get]
+func (h *DonationHandler) GetDonationStatement(c *gin.Context) {
+	year, ok := parseStatementYear(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid format"))
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	statement, err := h.statementService.GetDonationStatement(c.Request.Context(), userID.(int64), year)
+	if err != nil {
+		h.logger.Error("Failed to get donation statement", zap.Int("year", year), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get statement"))
+		return
+	}
+
+	var data []byte
+	var contentType string
+	switch format {
+	case "json":
+		c.JSON(http.StatusOK, response.SuccessResponse(statement))
+		return
+	case "csv":
+		data, err = h.statementService.RenderStatementCSV(statement)
+		contentType = "text/csv; charset=utf-8"
+	case "pdf":
+		data, err = h.statementService.RenderStatementPDF(statement)
+		contentType = "application/pdf"
+	}
+	if err != nil {
+		h.logger.Error("Failed to render donation statement", zap.String("format", format), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to render statement"))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%d.%s"`, year, format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// parseStatementYear reads the year query parameter, defaulting to the
+// current year, and writes a 400 response when it is invalid
+func parseStatementYear(c *gin.Context) (int, bool) {
+	currentYear := time.Now().Year()
+	year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(currentYear)))
+	if err != nil || year < 2000 || year > currentYear {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid year"))
+		return 0, false
+	}
+	return year, true
+}
+
+// GetCampaignSupportMessages godoc
+// @Summary Get a campaign's wall of support
+// @Description List approved donor messages and dedications on paid donations, newest first. Anonymous donors are shown as "Anonymous".
+// @Tags Donations
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} response.APIResponse{data=[]response.SupportMessageResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /public/campaigns/{id}/wall [get]
+func (h *DonationHandler) GetCampaignSupportMessages(c *gin.Context) {
+	campaignID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid campaign ID"))
+		return
+	}
+
+	limit, offset, ok := parsePagination(c, 20)
+	if !ok {
+		return
+	}
+
+	messages, err := h.donationService.GetCampaignSupportMessages(c.Request.Context(), campaignID, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrCampaignNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+			return
+		}
+		h.logger.Error("Failed to get wall of support", zap.Int64("campaign_id", campaignID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get messages"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(messages))
+}
+
+// GetCampaignPublicDonations godoc
+// @Summary Get a campaign's donor feed
+// @Description List paid donations to a campaign, newest first, net of refunds. Anonymous donors are shown as "Anonymous".
+// @Tags Donations
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} response.APIResponse{data=response.PaginatedResponse{items=[]response.PublicDonationResponse}}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /public/campaigns/{id}/donations [get]
+func (h *DonationHandler) GetCampaignPublicDonations(c *gin.Context) {
+	campaignID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid campaign ID"))
+		return
+	}
+
+	limit, offset, ok := parsePagination(c, 20)
+	if !ok {
+		return
+	}
+
+	page, err := h.donationService.GetCampaignPublicDonations(c.Request.Context(), campaignID, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrCampaignNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+			return
+		}
+		h.logger.Error("Failed to get campaign donations", zap.Int64("campaign_id", campaignID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get donations"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(page))
+}
+
+// GetCampaignLeaderboard godoc
+// @Summary Get a campaign's donor leaderboard
+// @Description Rank donors by the net amount given to a campaign within a time window. Anonymous donations are ranked without a name.
+// @Tags Donations
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param window query string false "24h, 7d, 30d, 90d, 365d or all" default(all)
+// @Param limit query int false "Number of donors" default(10)
+// @Success 200 {object} response.APIResponse{data=[]response.LeaderboardEntryResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /public/campaigns/{id}/leaderboard [get]
+func (h *DonationHandler) GetCampaignLeaderboard(c *gin.Context) {
+	campaignID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid campaign ID"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid limit"))
+		return
+	}
+
+	leaderboard, err := h.donationService.GetCampaignLeaderboard(c.Request.Context(), campaignID, c.DefaultQuery("window", "all"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidLeaderboardWindow):
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		case errors.Is(err, repository.ErrCampaignNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+		default:
+			h.logger.Error("Failed to get campaign leaderboard", zap.Int64("campaign_id", campaignID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get leaderboard"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(leaderboard))
+}
+
+// parsePagination reads the limit and offset query parameters and writes a
+// 400 response when they are invalid
+func parsePagination(c *gin.Context, defaultLimit int) (int, int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid limit"))
+		return 0, 0, false
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid offset"))
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type EmailHandler struct {
+	emailService *services.EmailService
+	logger       *zap.Logger
+}
+
+func NewEmailHandler(db *pgxpool.Pool, logger *zap.Logger, mailer services.Mailer) *EmailHandler {
+	return &EmailHandler{
+		emailService: NewEmailService(db, mailer),
+		logger:       logger,
+	}
+}
+
+// NewEmailService wires the email service, which is shared by the handlers
+// that queue emails and the email outbox job that delivers them.
+func NewEmailService(db *pgxpool.Pool, mailer services.Mailer) *services.EmailService {
+	cfg, _ := config.GetConfig()
+
+	return services.NewEmailService(
+		repository.NewEmailOutboxRepository(db, "public"),
+		repository.NewTxManager(db),
+		services.NewEmailTemplates(services.TemplateDir, cfg.MailConfig.DefaultLocale),
+		mailer,
+		int(cfg.MailConfig.MaxAttempts),
+	)
+}
+
+// ListEmails godoc
+// @Summary List outbound emails
+// @Description List queued, sent and failed emails, newest first (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param status query string false "Only emails in this status" Enums(pending, sent, failed)
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=[]models.OutboxEmail}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/emails [get]
+func (h *EmailHandler) ListEmails(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.EmailStatusPending, models.EmailStatusSent, models.EmailStatusFailed:
+	default:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid status"))
+		return
+	}
+	limit, offset, ok := parsePagination(c, 50)
+	if !ok {
+		return
+	}
+
+	emails, err := h.emailService.ListEmails(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list emails", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list emails"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(emails))
+}
+
+// GetEmail godoc
+// @Summary Get an outbound email
+// @Description Get an email with its delivery status. Bodies are not returned, they may contain account links (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param id path int true "Email ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=models.OutboxEmail}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/emails/{id} [get]
+func (h *EmailHandler) GetEmail(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid email ID"))
+		return
+	}
+
+	email, err := h.emailService.GetEmail(c.Request.Context(), id)
+	if err != nil {
+		h.emailError(c, "Failed to get email", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(email))
+}
+
+// RetryEmail godoc
+// @Summary Retry a failed email
+// @Description Queue a failed email again with a fresh set of delivery attempts (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param id path int true "Email ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=models.OutboxEmail}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/emails/{id}/retry [post]
+func (h *EmailHandler) RetryEmail(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid email ID"))
+		return
+	}
+
+	email, err := h.emailService.RetryEmail(c.Request.Context(), id)
+	if err != nil {
+		h.emailError(c, "Failed to retry email", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(email))
+}
+
+func (h *EmailHandler) emailError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrEmailNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Email not found"))
+	case errors.Is(err, services.ErrEmailNotFailed):
+		c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(message))
+	}
+}
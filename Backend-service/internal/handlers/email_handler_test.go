@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/services"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// memoryOutboxRepo keeps outbox emails in memory
+type memoryOutboxRepo struct {
+	mu     sync.Mutex
+	emails []models.OutboxEmail
+}
+
+func (r *memoryOutboxRepo) CreateEmail(ctx context.Context, email *models.OutboxEmail) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	email.ID = int64(len(r.emails) + 1)
+	email.Status = models.EmailStatusPending
+	email.NextAttemptAt = time.Now()
+	r.emails = append(r.emails, *email)
+	return nil
+}
+
+func (r *memoryOutboxRepo) LockDueEmails(ctx context.Context, now time.Time, limit int) ([]models.OutboxEmail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []models.OutboxEmail
+	for _, email := range r.emails {
+		if email.Status == models.EmailStatusPending && !email.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, email)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryOutboxRepo) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	email := &r.emails[id-1]
+	email.Status = models.EmailStatusSent
+	email.Attempts++
+	email.SentAt = &sentAt
+	return nil
+}
+
+func (r *memoryOutboxRepo) MarkAttemptFailed(ctx context.Context, id int64, status, lastError string, nextAttemptAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	email := &r.emails[id-1]
+	email.Status = status
+	email.Attempts++
+	email.LastError = lastError
+	email.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (r *memoryOutboxRepo) ListEmails(ctx context.Context, status string, limit, offset int) ([]models.OutboxEmail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var emails []models.OutboxEmail
+	for _, email := range r.emails {
+		if status == "" || email.Status == status {
+			emails = append(emails, email)
+		}
+	}
+	return emails, nil
+}
+
+func (r *memoryOutboxRepo) GetEmail(ctx context.Context, id int64) (*models.OutboxEmail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || int(id) > len(r.emails) {
+		return nil, nil
+	}
+	email := r.emails[id-1]
+	return &email, nil
+}
+
+func (r *memoryOutboxRepo) RequeueEmail(ctx context.Context, id int64, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	email := &r.emails[id-1]
+	email.Status = models.EmailStatusPending
+	email.Attempts = 0
+	email.NextAttemptAt = now
+	return nil
+}
+
+// noTxManager runs fn without a transaction
+type noTxManager struct{}
+
+func (noTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// recordingMailer keeps the messages it was asked to send
+type recordingMailer struct {
+	sent []services.EmailMessage
+}
+
+func (m *recordingMailer) Name() string { return "recording" }
+
+func (m *recordingMailer) Send(ctx context.Context, msg services.EmailMessage) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestEmailHandlerDoesNotExposeTokenLinks(t *testing.T) {
+	const link = "https://example.com/reset-password?token=secret-reset-token"
+
+	mailer := &recordingMailer{}
+	emailService := services.NewEmailService(
+		&memoryOutboxRepo{},
+		noTxManager{},
+		services.NewEmailTemplates(filepath.Join("..", "..", services.TemplateDir), "en"),
+		mailer,
+		3,
+	)
+	h := &EmailHandler{emailService: emailService, logger: zap.NewNop()}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/cms/emails", h.ListEmails)
+	r.GET("/cms/emails/:id", h.GetEmail)
+
+	ctx := context.Background()
+	email, err := emailService.Queue(ctx, "donor@example.com", "password_reset", "en", map[string]interface{}{
+		"Name":             "Donor",
+		"Link":             link,
+		"ExpiresInMinutes": 30,
+		"Company":          map[string]interface{}{"Name": "Share The Meal"},
+	})
+	if err != nil {
+		t.Fatalf("failed to queue email: %v", err)
+	}
+
+	assertNoLink := func(state string) {
+		t.Helper()
+		for _, path := range []string{"/cms/emails", "/cms/emails/1"} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET %s (%s) status = %d, want 200", path, state, w.Code)
+			}
+			if strings.Contains(w.Body.String(), "secret-reset-token") {
+				t.Errorf("GET %s (%s) exposes the reset link: %s", path, state, w.Body)
+			}
+		}
+	}
+
+	assertNoLink("pending")
+
+	if _, err := emailService.ProcessOutbox(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("failed to process outbox: %v", err)
+	}
+	if len(mailer.sent) != 1 || !strings.Contains(mailer.sent[0].TextBody, link) {
+		t.Fatalf("email %d was not delivered with its reset link", email.ID)
+	}
+
+	assertNoLink("sent")
+}
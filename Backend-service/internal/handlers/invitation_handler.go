@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/dto/request"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+	logger            *zap.Logger
+}
+
+func NewInvitationHandler(db *pgxpool.Pool, logger *zap.Logger, mailer services.Mailer) *InvitationHandler {
+	cfg, _ := config.GetConfig()
+
+	return &InvitationHandler{
+		invitationService: services.NewInvitationService(
+			repository.NewInvitationRepository(db, "public"),
+			repository.NewUserRepository(db, "public"),
+			repository.NewRoleRepository(db, "public"),
+			repository.NewTxManager(db),
+			NewEmailService(db, mailer),
+			cfg.Invitation,
+		),
+		logger: logger,
+	}
+}
+
+// CreateInvitation godoc
+// @Summary Invite a recipient or superadmin
+// @Description Email a signed, expiring invitation link for the recipient or superadmin role. Earlier open invitations for the address are revoked (Superadmin only)
+// @Tags CMS
+// @Accept json
+// @Produce json
+// @Param request body request.CreateInvitationRequest true "Invitation"
+// @Security BearerAuth
+// @Success 201 {object} response.APIResponse{data=response.InvitationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req request.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(c.Request.Context(), req, c.GetString("username"))
+	if err != nil {
+		h.invitationError(c, "Failed to create invitation", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse(invitation))
+}
+
+// ListInvitations godoc
+// @Summary List invitations
+// @Description List invitations with their status, newest first (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=[]response.InvitationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/invitations [get]
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	limit, offset, ok := parsePagination(c, 50)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.invitationService.ListInvitations(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list invitations", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list invitations"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(invitations))
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Make the link of a pending invitation stop working (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.InvitationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/invitations/{id}/revoke [post]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid invitation ID"))
+		return
+	}
+
+	invitation, err := h.invitationService.RevokeInvitation(c.Request.Context(), id, c.GetString("username"))
+	if err != nil {
+		h.invitationError(c, "Failed to revoke invitation", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(invitation))
+}
+
+// POST /api/v1/auth-management/accept-invitation
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req request.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.Meta{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request body",
+			Status:  "error",
+		})
+		return
+	}
+
+	user, err := h.invitationService.AcceptInvitation(c.Request.Context(), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorMessage := "Internal server error"
+
+		switch {
+		case errors.Is(err, services.ErrInvalidInvitation):
+			statusCode = http.StatusUnauthorized
+			errorMessage = "Invalid or expired invitation"
+		case errors.Is(err, services.ErrUsernameAlreadyTaken):
+			statusCode = http.StatusConflict
+			errorMessage = "Username already exists"
+		case errors.Is(err, services.ErrEmailAlreadyRegistered):
+			statusCode = http.StatusConflict
+			errorMessage = "Email already exists"
+		default:
+			h.logger.Error("Failed to accept invitation", zap.String("username", req.Username), zap.Error(err))
+		}
+
+		c.JSON(statusCode, response.Meta{
+			Code:    statusCode,
+			Message: errorMessage,
+			Status:  http.StatusText(statusCode),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.APIResponse{
+		Data: user,
+		Meta: response.Meta{
+			Code:    http.StatusCreated,
+			Message: "Account created",
+			Status:  http.StatusText(http.StatusCreated),
+		},
+	})
+}
+
+func (h *InvitationHandler) invitationError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Invitation not found"))
+	case errors.Is(err, services.ErrInvalidInvitationRole):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+	case errors.Is(err, services.ErrInvitationNotPending), errors.Is(err, services.ErrEmailAlreadyRegistered):
+		c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(message))
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/services"
+	"share-the-meal/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const webhookSignatureHeader = "X-Signature"
+
+type PaymentHandler struct {
+	webhookService *services.PaymentWebhookService
+	logger         *zap.Logger
+}
+
+func NewPaymentHandler(db *pgxpool.Pool, logger *zap.Logger, hub *utils.Hub, gateway services.PaymentGateway) *PaymentHandler {
+	donationRepo := repository.NewDonationRepository(db, "public")
+	refundRepo := repository.NewDonationRefundRepository(db, "public")
+	campaignRepo := repository.NewCampaignRepository(db, "public")
+	notificationRepo := repository.NewNotificationsRepository(db, "public")
+	eventRepo := repository.NewPaymentWebhookEventRepository(db, "public")
+	rateRepo := repository.NewExchangeRateRepository(db, "public")
+	txManager := repository.NewTxManager(db)
+	cfg, _ := config.GetConfig()
+
+	donationService := services.NewDonationService(donationRepo, refundRepo, campaignRepo, notificationRepo, txManager, gateway, services.NewExchangeRateService(rateRepo), hub)
+
+	return &PaymentHandler{
+		webhookService: services.NewPaymentWebhookService(eventRepo, donationService, txManager, gateway, cfg.PaymentConfig.WebhookSecret),
+		logger:         logger,
+	}
+}
+
+// HandleWebhook godoc
+// @Summary Receive payment provider webhook
+// @Description Verify the HMAC-SHA256 signature in X-Signature and apply the payment status exactly once
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param provider path string true "Payment provider"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /payments/webhook/{provider} [post]
+func (h *PaymentHandler) HandleWebhook(c *gin.Context) {
+	provider := c.Param("provider")
+
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	err = h.webhookService.HandleWebhook(c.Request.Context(), provider, payload, c.GetHeader(webhookSignatureHeader))
+	if err != nil {
+		h.logger.Error("Failed to process payment webhook", zap.String("provider", provider), zap.Error(err))
+
+		switch {
+		case errors.Is(err, services.ErrUnknownPaymentProvider):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Unknown payment provider"))
+		case errors.Is(err, services.ErrInvalidWebhookSignature):
+			c.JSON(http.StatusUnauthorized, response.ErrorResponseWithCode(http.StatusUnauthorized, "Invalid signature"))
+		case errors.Is(err, services.ErrInvalidWebhookPayload):
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid payload"))
+		case errors.Is(err, services.ErrDonationNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Donation not found"))
+		case errors.Is(err, services.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to process webhook"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("Webhook processed"))
+}
+
+// ListWebhookEvents godoc
+// @Summary List payment webhook events
+// @Description List received payment webhook events, newest first (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=[]models.PaymentWebhookEvent}
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/payments/webhook-events [get]
+func (h *PaymentHandler) ListWebhookEvents(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid limit"))
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid offset"))
+		return
+	}
+
+	events, err := h.webhookService.ListEvents(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list webhook events", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list webhook events"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(events))
+}
+
+// GetWebhookEvent godoc
+// @Summary Get a payment webhook event
+// @Description Get a received payment webhook event with its raw payload (Superadmin only)
+// @Tags CMS
+// @Produce json
+// @Param id path int true "Webhook event ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=models.PaymentWebhookEvent}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /cms/payments/webhook-events/{id} [get]
+func (h *PaymentHandler) GetWebhookEvent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid event ID"))
+		return
+	}
+
+	event, err := h.webhookService.GetEvent(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get webhook event", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get webhook event"))
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Webhook event not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(event))
+}
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"share-the-meal/internal/dto/request"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/services"
+	"share-the-meal/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// RecipientHandler serves the campaign routes of recipients, who manage only
+// the campaigns they own
+type RecipientHandler struct {
+	campaignService *services.CampaignService
+	donationService *services.DonationService
+	logger          *zap.Logger
+}
+
+func NewRecipientHandler(db *pgxpool.Pool, logger *zap.Logger, hub *utils.Hub, gateway services.PaymentGateway) *RecipientHandler {
+	campaignRepo := repository.NewCampaignRepository(db, "public")
+	donationRepo := repository.NewDonationRepository(db, "public")
+	refundRepo := repository.NewDonationRefundRepository(db, "public")
+	notificationRepo := repository.NewNotificationsRepository(db, "public")
+	rateRepo := repository.NewExchangeRateRepository(db, "public")
+	txManager := repository.NewTxManager(db)
+
+	return &RecipientHandler{
+		campaignService: NewCampaignService(db),
+		donationService: services.NewDonationService(donationRepo, refundRepo, campaignRepo, notificationRepo, txManager, gateway, services.NewExchangeRateService(rateRepo), hub),
+		logger:          logger,
+	}
+}
+
+// ListCampaigns godoc
+// @Summary List own campaigns
+// @Description List the campaigns of the current recipient in every status (Recipient only)
+// @Tags Recipient
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=[]response.CampaignResponse}
+// @Failure 500 {object} response.APIResponse
+// @Router /recipient/campaigns [get]
+func (h *RecipientHandler) ListCampaigns(c *gin.Context) {
+	campaigns, err := h.campaignService.ListRecipientCampaigns(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		h.logger.Error("Failed to list campaigns", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list campaigns"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaigns))
+}
+
+// CreateCampaign godoc
+// @Summary Create a draft campaign
+// @Description Create a draft campaign owned by the current recipient. It stays private until submitted and approved (Recipient only)
+// @Tags Recipient
+// @Accept multipart/form-data
+// @Produce json
+// @Param title formData string true "Campaign title"
+// @Param description formData string true "Campaign description"
+// @Param short_description formData string false "Short description"
+// @Param target formData number true "Target amount"
+// @Param currency formData string false "ISO 4217 currency code"
+// @Param start_date formData string false "Start date (RFC 3339), defaults to now"
+// @Param end_date formData string true "End date (RFC 3339)"
+// @Param image formData file true "Campaign image"
+// @Security BearerAuth
+// @Success 201 {object} response.APIResponse{data=response.CampaignResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /recipient/campaigns [post]
+func (h *RecipientHandler) CreateCampaign(c *gin.Context) {
+	var req request.CreateCampaignRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	file, err := utils.HandleFileUpload(c, "image")
+	if err != nil {
+		h.logger.Error("File upload error", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Image is required"))
+		return
+	}
+
+	campaign, err := h.campaignService.CreateRecipientCampaign(c.Request.Context(), req, file, c.GetInt64("userID"), c.GetString("username"))
+	if err != nil {
+		h.logger.Error("Failed to create campaign", zap.Error(err))
+		if errors.Is(err, services.ErrUnsupportedCurrency) || errors.Is(err, services.ErrInvalidCampaignDates) {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to create campaign"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse(campaign))
+}
+
+// GetCampaign godoc
+// @Summary Get an own campaign
+// @Description Get a campaign of the current recipient in any status (Recipient only)
+// @Tags Recipient
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.CampaignResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /recipient/campaigns/{id} [get]
+func (h *RecipientHandler) GetCampaign(c *gin.Context) {
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	campaign, err := h.campaignService.GetRecipientCampaign(c.Request.Context(), id, c.GetInt64("userID"))
+	if err != nil {
+		h.campaignError(c, "Failed to get campaign", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaign))
+}
+
+// UpdateCampaign godoc
+// @Summary Update an own draft campaign
+// @Description Update a draft campaign of the current recipient. Submitted campaigns can only be changed by a superadmin (Recipient only)
+// @Tags Recipient
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param title formData string false "Campaign title"
+// @Param description formData string false "Campaign description"
+// @Param short_description formData string false "Short description"
+// @Param target formData number false "Target amount"
+// @Param currency formData string false "ISO 4217 currency code"
+// @Param start_date formData string false "Start date (RFC 3339)"
+// @Param end_date formData string false "End date (RFC 3339)"
+// @Param image formData file false "Campaign image"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.CampaignResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /recipient/campaigns/{id} [put]
+func (h *RecipientHandler) UpdateCampaign(c *gin.Context) {
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateCampaignRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	var file *multipart.FileHeader
+	if formFile, err := c.FormFile("image"); err == nil {
+		file = formFile
+	}
+
+	campaign, err := h.campaignService.UpdateRecipientCampaign(c.Request.Context(), id, c.GetInt64("userID"), req, file, c.GetString("username"))
+	if err != nil {
+		h.campaignError(c, "Failed to update campaign", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaign))
+}
+
+// SubmitCampaign godoc
+// @Summary Submit a campaign for approval
+// @Description Send a draft campaign of the current recipient to a superadmin for review (Recipient only)
+// @Tags Recipient
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.CampaignResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /recipient/campaigns/{id}/submit [post]
+func (h *RecipientHandler) SubmitCampaign(c *gin.Context) {
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	campaign, err := h.campaignService.SubmitCampaign(c.Request.Context(), id, c.GetInt64("userID"), c.GetString("username"))
+	if err != nil {
+		h.campaignError(c, "Failed to submit campaign", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(campaign))
+}
+
+// GetCampaignDonations godoc
+// @Summary List donations to an own campaign
+// @Description List paid donations to a campaign of the current recipient, newest first and net of refunds. Anonymous donors are not named (Recipient only)
+// @Tags Recipient
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.PaginatedResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /recipient/campaigns/{id}/donations [get]
+func (h *RecipientHandler) GetCampaignDonations(c *gin.Context) {
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+	limit, offset, ok := parsePagination(c, 20)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.campaignService.GetRecipientCampaign(ctx, id, c.GetInt64("userID")); err != nil {
+		h.campaignError(c, "Failed to get campaign", err)
+		return
+	}
+
+	page, err := h.donationService.GetRecipientCampaignDonations(ctx, id, limit, offset)
+	if err != nil {
+		h.campaignError(c, "Failed to get donations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(page))
+}
+
+// GetCampaignStats godoc
+// @Summary Get statistics of an own campaign
+// @Description Get the statistics of a campaign of the current recipient, net of refunds (Recipient only)
+// @Tags Recipient
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.CampaignStatsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /recipient/campaigns/{id}/stats [get]
+func (h *RecipientHandler) GetCampaignStats(c *gin.Context) {
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.campaignService.GetRecipientCampaign(ctx, id, c.GetInt64("userID")); err != nil {
+		h.campaignError(c, "Failed to get campaign", err)
+		return
+	}
+
+	stats, err := h.donationService.GetCampaignStats(ctx, id)
+	if err != nil {
+		h.campaignError(c, "Failed to get stats", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(stats))
+}
+
+// campaignError writes the response for an error of the recipient campaign services
+func (h *RecipientHandler) campaignError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCampaignNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+	case errors.Is(err, services.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrCurrencyLocked),
+		errors.Is(err, services.ErrInvalidCampaignDates),
+		errors.Is(err, services.ErrCampaignEnded):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+	case errors.Is(err, services.ErrCampaignNotEditable),
+		errors.Is(err, services.ErrInvalidCampaignStatusTransition):
+		c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse(message))
+	}
+}
+
+func parseCampaignID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid campaign ID"))
+		return 0, false
+	}
+	return id, true
+}
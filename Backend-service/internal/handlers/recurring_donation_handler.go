@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"share-the-meal/internal/dto/request"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/services"
+	"share-the-meal/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type RecurringDonationHandler struct {
+	recurringService *services.RecurringDonationService
+	logger           *zap.Logger
+}
+
+func NewRecurringDonationHandler(db *pgxpool.Pool, logger *zap.Logger, hub *utils.Hub, gateway services.PaymentGateway) *RecurringDonationHandler {
+	return &RecurringDonationHandler{
+		recurringService: NewRecurringDonationService(db, hub, gateway),
+		logger:           logger,
+	}
+}
+
+// NewRecurringDonationService wires the recurring donation service, which is
+// shared by the HTTP handler and the background scheduler.
+func NewRecurringDonationService(db *pgxpool.Pool, hub *utils.Hub, gateway services.PaymentGateway) *services.RecurringDonationService {
+	donationRepo := repository.NewDonationRepository(db, "public")
+	refundRepo := repository.NewDonationRefundRepository(db, "public")
+	campaignRepo := repository.NewCampaignRepository(db, "public")
+	notificationRepo := repository.NewNotificationsRepository(db, "public")
+	rateRepo := repository.NewExchangeRateRepository(db, "public")
+	recurringRepo := repository.NewRecurringDonationRepository(db, "public")
+	txManager := repository.NewTxManager(db)
+
+	donationService := services.NewDonationService(donationRepo, refundRepo, campaignRepo, notificationRepo, txManager, gateway, services.NewExchangeRateService(rateRepo), hub)
+
+	return services.NewRecurringDonationService(recurringRepo, campaignRepo, donationService, txManager)
+}
+
+// CreateRecurringDonation godoc
+// @Summary Create a recurring donation
+// @Description Schedule a donation that is charged every interval, starting at start_date or now
+// @Tags Donations
+// @Accept json
+// @Produce json
+// @Param request body request.RecurringDonationRequest true "Recurring donation"
+// @Security BearerAuth
+// @Success 201 {object} response.APIResponse{data=response.RecurringDonationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /donations/recurring [post]
+func (h *RecurringDonationHandler) CreateRecurringDonation(c *gin.Context) {
+	var req request.RecurringDonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid request"))
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	recurring, err := h.recurringService.CreateRecurringDonation(c.Request.Context(), req, userID.(int64), c.GetString("username"))
+	if err != nil {
+		h.logger.Error("Failed to create recurring donation", zap.Error(err))
+		if errors.Is(err, services.ErrUnsupportedCurrency) || errors.Is(err, services.ErrCampaignNotActive) {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+			return
+		}
+		if errors.Is(err, repository.ErrCampaignNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Campaign not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to create recurring donation"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse(recurring))
+}
+
+// GetUserRecurringDonations godoc
+// @Summary List recurring donations
+// @Description List the recurring donations of the current user
+// @Tags Donations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=[]response.RecurringDonationResponse}
+// @Failure 500 {object} response.APIResponse
+// @Router /donations/recurring [get]
+func (h *RecurringDonationHandler) GetUserRecurringDonations(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	recurring, err := h.recurringService.GetUserRecurringDonations(c.Request.Context(), userID.(int64))
+	if err != nil {
+		h.logger.Error("Failed to get recurring donations", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to get recurring donations"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(recurring))
+}
+
+// PauseRecurringDonation godoc
+// @Summary Pause a recurring donation
+// @Tags Donations
+// @Produce json
+// @Param id path int true "Recurring donation ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.RecurringDonationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /donations/recurring/{id}/pause [post]
+func (h *RecurringDonationHandler) PauseRecurringDonation(c *gin.Context) {
+	h.changeStatus(c, h.recurringService.PauseRecurringDonation)
+}
+
+// ResumeRecurringDonation godoc
+// @Summary Resume a paused recurring donation
+// @Description Periods missed while paused are skipped
+// @Tags Donations
+// @Produce json
+// @Param id path int true "Recurring donation ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.RecurringDonationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /donations/recurring/{id}/resume [post]
+func (h *RecurringDonationHandler) ResumeRecurringDonation(c *gin.Context) {
+	h.changeStatus(c, h.recurringService.ResumeRecurringDonation)
+}
+
+// CancelRecurringDonation godoc
+// @Summary Cancel a recurring donation
+// @Tags Donations
+// @Produce json
+// @Param id path int true "Recurring donation ID"
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=response.RecurringDonationResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /donations/recurring/{id}/cancel [post]
+func (h *RecurringDonationHandler) CancelRecurringDonation(c *gin.Context) {
+	h.changeStatus(c, h.recurringService.CancelRecurringDonation)
+}
+
+type recurringStatusChange func(ctx context.Context, id, userID int64, username string) (*response.RecurringDonationResponse, error)
+
+func (h *RecurringDonationHandler) changeStatus(c *gin.Context, change recurringStatusChange) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid recurring donation ID"))
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse("Unauthorized"))
+		return
+	}
+
+	recurring, err := change(c.Request.Context(), id, userID.(int64), c.GetString("username"))
+	if err != nil {
+		h.logger.Error("Failed to update recurring donation", zap.Int64("id", id), zap.Error(err))
+		switch {
+		case errors.Is(err, services.ErrRecurringDonationNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Recurring donation not found"))
+		case errors.Is(err, services.ErrInvalidRecurringStatus):
+			c.JSON(http.StatusConflict, response.ErrorResponseWithCode(http.StatusConflict, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to update recurring donation"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse(recurring))
+}
@@ -1,26 +1,31 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
     "share-the-meal/internal/dto/response"
     "share-the-meal/internal/repository"
     "share-the-meal/internal/services"
 
+    "strconv"
+
     "github.com/gin-gonic/gin"
     "github.com/jackc/pgx/v5/pgxpool"
     "go.uber.org/zap"
 )
 
 type UserHandler struct {
-    userService *services.UserService
-    logger      *zap.Logger
+    userService    *services.UserService
+    sessionService *services.SessionService
+    logger         *zap.Logger
 }
 
 func NewUserHandler(db *pgxpool.Pool, logger *zap.Logger) *UserHandler {
     userRepo := repository.NewUserRepository(db, "public")
     return &UserHandler{
-        userService: services.NewUserService(userRepo),
-        logger:      logger,
+        userService:    services.NewUserService(userRepo),
+        sessionService: NewSessionService(db),
+        logger:         logger,
     }
 }
 
@@ -37,4 +42,36 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
 
 func (h *UserHandler) UpdateUserProfile(c *gin.Context) {
     // Implementasi update profile
-}
\ No newline at end of file
+}
+// GET /api/v1/users/sessions
+func (h *UserHandler) ListSessions(c *gin.Context) {
+    userID := c.MustGet("userID").(int64)
+    sessions, err := h.sessionService.ListSessions(c.Request.Context(), userID, c.GetInt64("sessionID"))
+    if err != nil {
+        h.logger.Error("Failed to list sessions", zap.Error(err))
+        c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to list sessions"))
+        return
+    }
+    c.JSON(http.StatusOK, response.SuccessResponse(sessions))
+}
+
+// DELETE /api/v1/users/sessions/:id
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+    userID := c.MustGet("userID").(int64)
+    sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, response.ErrorResponse("Invalid session ID"))
+        return
+    }
+
+    if err := h.sessionService.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+        if errors.Is(err, services.ErrSessionNotFound) {
+            c.JSON(http.StatusNotFound, response.ErrorResponseWithCode(http.StatusNotFound, "Session not found"))
+            return
+        }
+        h.logger.Error("Failed to revoke session", zap.Error(err))
+        c.JSON(http.StatusInternalServerError, response.ErrorResponse("Failed to revoke session"))
+        return
+    }
+    c.JSON(http.StatusOK, response.SuccessResponse("Session revoked"))
+}
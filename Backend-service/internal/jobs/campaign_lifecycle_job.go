@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"share-the-meal/internal/services"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CampaignLifecycleJob opens scheduled campaigns and closes ended ones by date
+type CampaignLifecycleJob struct {
+	campaignService *services.CampaignService
+	logger          *zap.Logger
+}
+
+func NewCampaignLifecycleJob(campaignService *services.CampaignService, logger *zap.Logger) *CampaignLifecycleJob {
+	return &CampaignLifecycleJob{
+		campaignService: campaignService,
+		logger:          logger,
+	}
+}
+
+func (j *CampaignLifecycleJob) Name() string {
+	return "campaign-lifecycle"
+}
+
+func (j *CampaignLifecycleJob) Run(ctx context.Context) error {
+	activated, closed, err := j.campaignService.ApplyLifecycle(ctx, time.Now())
+	if activated > 0 || closed > 0 {
+		j.logger.Info("Updated campaign statuses", zap.Int64("activated", activated), zap.Int64("closed", closed))
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"share-the-meal/internal/services"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EmailOutboxJob delivers queued emails and retries the ones that failed
+type EmailOutboxJob struct {
+	emailService *services.EmailService
+	logger       *zap.Logger
+}
+
+func NewEmailOutboxJob(emailService *services.EmailService, logger *zap.Logger) *EmailOutboxJob {
+	return &EmailOutboxJob{
+		emailService: emailService,
+		logger:       logger,
+	}
+}
+
+func (j *EmailOutboxJob) Name() string {
+	return "email-outbox"
+}
+
+func (j *EmailOutboxJob) Run(ctx context.Context) error {
+	sent, err := j.emailService.ProcessOutbox(ctx, time.Now())
+	if sent > 0 {
+		j.logger.Info("Sent queued emails", zap.Int("count", sent))
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"share-the-meal/internal/services"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RecurringDonationJob generates the donations of recurring schedules that are due
+type RecurringDonationJob struct {
+	recurringService *services.RecurringDonationService
+	logger           *zap.Logger
+}
+
+func NewRecurringDonationJob(recurringService *services.RecurringDonationService, logger *zap.Logger) *RecurringDonationJob {
+	return &RecurringDonationJob{
+		recurringService: recurringService,
+		logger:           logger,
+	}
+}
+
+func (j *RecurringDonationJob) Name() string {
+	return "recurring-donations"
+}
+
+func (j *RecurringDonationJob) Run(ctx context.Context) error {
+	processed, err := j.recurringService.ProcessDueDonations(ctx, time.Now())
+	if processed > 0 {
+		j.logger.Info("Generated recurring donations", zap.Int("count", processed))
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job is a unit of background work run periodically by the Scheduler
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+type scheduledJob struct {
+	job      Job
+	interval time.Duration
+}
+
+// Scheduler runs registered jobs on fixed intervals inside the server process
+type Scheduler struct {
+	jobs   []scheduledJob
+	logger *zap.Logger
+}
+
+func NewScheduler(logger *zap.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Register adds a job that runs every interval. Jobs must be registered before
+// Start. A job with a non-positive interval is logged and never run.
+func (s *Scheduler) Register(job Job, interval time.Duration) {
+	if interval <= 0 {
+		s.logger.Error("Background job has no valid interval, not scheduling it", zap.String("job", job.Name()), zap.Duration("interval", interval))
+		return
+	}
+	s.jobs = append(s.jobs, scheduledJob{job: job, interval: interval})
+}
+
+// Start runs every job once immediately and then on its interval until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		go s.loop(ctx, j)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, j scheduledJob) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		s.run(ctx, j.job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Background job panicked", zap.String("job", job.Name()), zap.Any("panic", r))
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		s.logger.Error("Background job failed", zap.String("job", job.Name()), zap.Error(err))
+	}
+}
@@ -33,6 +33,9 @@ func AuthMiddleware() gin.HandlerFunc {
 			if userID, ok := claims["id"].(float64); ok {
 				c.Set("userID", int64(userID))
 			}
+			if sessionID, ok := claims["sid"].(float64); ok {
+				c.Set("sessionID", int64(sessionID))
+			}
 			if username, ok := claims["username"].(string); ok {
 				c.Set("username", username)
 			}
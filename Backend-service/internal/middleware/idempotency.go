@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// responseRecorder keeps a copy of everything the handler writes
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware replays the stored response when an authenticated
+// client retries a request with the same Idempotency-Key header. Requests
+// without the header are passed through unchanged. Must run after AuthMiddleware.
+func IdempotencyMiddleware(repo repository.IdempotencyKeyRepositoryInterface, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		uid := userID.(int64)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(append([]byte(c.Request.Method+" "+c.FullPath()+"\n"), body...))
+		requestHash := hex.EncodeToString(hash[:])
+
+		ctx := c.Request.Context()
+		reserved, err := repo.Reserve(ctx, &models.IdempotencyKey{
+			UserID:      uid,
+			Key:         key,
+			RequestHash: requestHash,
+			ExpiresAt:   time.Now().Add(ttl),
+		})
+		if err != nil {
+			log.Printf("Failed to reserve idempotency key: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if !reserved {
+			existing, err := repo.GetKey(ctx, uid, key)
+			if err != nil || existing == nil {
+				log.Printf("Failed to load idempotency key: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+
+			switch {
+			case existing.RequestHash != requestHash:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was already used with a different request"})
+			case !existing.IsCompleted():
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+			default:
+				c.Header(IdempotencyReplayedHeader, "true")
+				c.Data(*existing.StatusCode, "application/json; charset=utf-8", existing.ResponseBody)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// Server errors and responses that could not be stored release the
+		// key so the client can retry with it. The deferred release also runs
+		// when the handler panics, before gin.Recovery turns the panic into a 500.
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := repo.Release(context.WithoutCancel(ctx), uid, key); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		// The handler has done its work, a client that hung up must not keep
+		// the response from being stored
+		if err := repo.SaveResponse(context.WithoutCancel(ctx), uid, key, status, recorder.body.Bytes()); err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+			return
+		}
+		completed = true
+	}
+}
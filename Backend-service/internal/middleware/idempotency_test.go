@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"share-the-meal/internal/models"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryIdempotencyRepo keeps idempotency keys in memory, keyed by user and key
+type memoryIdempotencyRepo struct {
+	mu      sync.Mutex
+	keys    map[string]*models.IdempotencyKey
+	saveErr error
+}
+
+func newMemoryIdempotencyRepo() *memoryIdempotencyRepo {
+	return &memoryIdempotencyRepo{keys: make(map[string]*models.IdempotencyKey)}
+}
+
+func (r *memoryIdempotencyRepo) id(userID int64, key string) string {
+	return fmt.Sprintf("%d/%s", userID, key)
+}
+
+func (r *memoryIdempotencyRepo) Reserve(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.keys[r.id(key.UserID, key.Key)]; ok && existing.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+	stored := *key
+	r.keys[r.id(key.UserID, key.Key)] = &stored
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepo) GetKey(ctx context.Context, userID int64, key string) (*models.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.keys[r.id(userID, key)]
+	if !ok {
+		return nil, nil
+	}
+	stored := *existing
+	return &stored, nil
+}
+
+func (r *memoryIdempotencyRepo) SaveResponse(ctx context.Context, userID int64, key string, statusCode int, body []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Like a database call, saving fails once the context is cancelled
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+
+	if existing, ok := r.keys[r.id(userID, key)]; ok {
+		existing.StatusCode = &statusCode
+		existing.ResponseBody = body
+	}
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) Release(ctx context.Context, userID int64, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keys, r.id(userID, key))
+	return nil
+}
+
+func newIdempotencyTestRouter(repo *memoryIdempotencyRepo, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.POST("/donations", func(c *gin.Context) {
+		c.Set("userID", int64(1))
+	}, IdempotencyMiddleware(repo, time.Hour), handler)
+	return r
+}
+
+func postWithKey(r *gin.Engine, key string) *httptest.ResponseRecorder {
+	return postWithKeyContext(context.Background(), r, key)
+}
+
+func postWithKeyContext(ctx context.Context, r *gin.Engine, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/donations", strings.NewReader(`{"amount":"10.00"}`)).WithContext(ctx)
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	repo := newMemoryIdempotencyRepo()
+	calls := 0
+	r := newIdempotencyTestRouter(repo, func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("database went away")
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	if w := postWithKey(r, "retry-me"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first request status = %d, want 500", w.Code)
+	}
+	if key, _ := repo.GetKey(context.Background(), 1, "retry-me"); key != nil {
+		t.Fatalf("key is still reserved after the handler panicked")
+	}
+
+	w := postWithKey(r, "retry-me")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, want 201", w.Code)
+	}
+	if calls != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyReplaysCompletedResponse(t *testing.T) {
+	repo := newMemoryIdempotencyRepo()
+	calls := 0
+	r := newIdempotencyTestRouter(repo, func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	first := postWithKey(r, "once")
+	second := postWithKey(r, "once")
+
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("replayed response = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Fatalf("replayed response is missing the %s header", IdempotencyReplayedHeader)
+	}
+}
+
+func TestIdempotencyStoresResponseAfterClientHungUp(t *testing.T) {
+	repo := newMemoryIdempotencyRepo()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := newIdempotencyTestRouter(repo, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+		cancel()
+	})
+
+	postWithKeyContext(ctx, r, "hung-up")
+
+	key, _ := repo.GetKey(context.Background(), 1, "hung-up")
+	if key == nil || !key.IsCompleted() {
+		t.Fatalf("response was not stored after the request context was cancelled")
+	}
+}
+
+func TestIdempotencyReleasesKeyWhenResponseIsNotStored(t *testing.T) {
+	repo := newMemoryIdempotencyRepo()
+	repo.saveErr = errors.New("database went away")
+	calls := 0
+	r := newIdempotencyTestRouter(repo, func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	if w := postWithKey(r, "unsaved"); w.Code != http.StatusCreated {
+		t.Fatalf("first request status = %d, want 201", w.Code)
+	}
+	if key, _ := repo.GetKey(context.Background(), 1, "unsaved"); key != nil {
+		t.Fatalf("key is still reserved after its response could not be stored")
+	}
+
+	repo.saveErr = nil
+	if w := postWithKey(r, "unsaved"); w.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, want 201", w.Code)
+	}
+	if calls != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls)
+	}
+}
@@ -2,17 +2,43 @@ package middleware
 
 import (
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// redactedQueryParams carry one-time secrets, such as email verification
+// tokens, that must not be written to the access log
+var redactedQueryParams = []string{"token"}
+
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "[unparsable]"
+	}
+	redacted := false
+	for _, param := range redactedQueryParams {
+		if values.Has(param) {
+			values.Set(param, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
 func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := redactQuery(c.Request.URL.RawQuery)
 
 		c.Next()
 
@@ -44,4 +70,4 @@ func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
 			logger.Info("Request handled", logFields...)
 		}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import "testing"
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"page=2&limit=10", "page=2&limit=10"},
+		{"token=secret", "token=REDACTED"},
+		{"token=secret&lang=en", "lang=en&token=REDACTED"},
+		{"token=a&token=b", "token=REDACTED"},
+		{"token=%zz", "[unparsable]"},
+	}
+
+	for _, tt := range tests {
+		if got := redactQuery(tt.in); got != tt.want {
+			t.Errorf("redactQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
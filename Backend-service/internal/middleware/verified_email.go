@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifiedEmailMiddleware rejects users whose email address is not verified
+// unless the policy allows them. The user is read from the database so a
+// verification takes effect without signing in again. Must run after AuthMiddleware.
+func VerifiedEmailMiddleware(userRepo repository.UserRepositoryInterface, policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy == config.UnverifiedAllow {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		user, err := userRepo.GetUserByID(c.Request.Context(), userID.(int64))
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				return
+			}
+			log.Printf("Failed to load user for email verification check: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if !user.IsEmailVerified() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Please verify your email address first"})
+			return
+		}
+
+		c.Next()
+	}
+}
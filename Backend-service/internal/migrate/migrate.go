@@ -0,0 +1,360 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// advisoryLockID identifies the session lock held while migrating, so that
+// replicas starting together apply each migration only once
+const advisoryLockID int64 = 7_316_012_477_521_830
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var (
+	ErrDirty            = errors.New("database is dirty, fix it manually and run force")
+	ErrUnknownVersion   = errors.New("unknown migration version")
+	ErrMissingDownFile  = errors.New("migration has no down file")
+	ErrDuplicateVersion = errors.New("duplicate migration version")
+)
+
+// Migration is a pair of golang-migrate style files, NNNN_name.up.sql and NNNN_name.down.sql
+type Migration struct {
+	Version  uint64
+	Name     string
+	UpFile   string
+	DownFile string
+}
+
+// MigrationStatus is a migration together with whether it has been applied
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrator applies the SQL migrations in fsys. It keeps its state in a
+// golang-migrate compatible schema_migrations table, so databases migrated
+// with the golang-migrate CLI can be taken over as they are.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	fsys       fs.FS
+	migrations []Migration
+	logger     *zap.Logger
+}
+
+func NewMigrator(pool *pgxpool.Pool, fsys fs.FS, logger *zap.Logger) (*Migrator, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		pool:       pool,
+		fsys:       fsys,
+		migrations: migrations,
+		logger:     logger,
+	}, nil
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+		}
+
+		if match[3] == "up" {
+			m.UpFile = entry.Name()
+		} else {
+			m.DownFile = entry.Name()
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpFile == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies the next n pending migrations, or all of them when n is 0
+func (m *Migrator) Up(ctx context.Context, n int) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= current {
+				continue
+			}
+			if n > 0 && applied == n {
+				break
+			}
+			if err := m.apply(ctx, conn, migration, migration.UpFile, migration.Version); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the last n applied migrations
+func (m *Migrator) Down(ctx context.Context, n int) (int, error) {
+	rolledBack := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for rolledBack < n && current > 0 {
+			if current, err = m.rollback(ctx, conn, current); err != nil {
+				return err
+			}
+			rolledBack++
+		}
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// To migrates up or down until version is the latest applied migration.
+// Version 0 rolls back every migration.
+func (m *Migrator) To(ctx context.Context, version uint64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for current > version {
+			if current, err = m.rollback(ctx, conn, current); err != nil {
+				return err
+			}
+		}
+		for _, migration := range m.migrations {
+			if migration.Version <= current || migration.Version > version {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration, migration.UpFile, migration.Version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Force records version as applied and clears the dirty flag without running
+// any SQL. It is used to recover after a migration failed halfway.
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			return setVersion(ctx, tx, version)
+		})
+	})
+}
+
+// Status returns the applied version, whether it is dirty and every known migration
+func (m *Migrator) Status(ctx context.Context) (uint64, bool, []MigrationStatus, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return 0, false, nil, err
+	}
+	defer conn.Release()
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return 0, false, nil, err
+	}
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, false, nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: migration.Version <= version})
+	}
+
+	return version, dirty, statuses, nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockID); err != nil {
+			m.logger.Error("Failed to release migration lock", zap.Error(err))
+		}
+	}()
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// currentVersion returns the applied version, refusing to go on when a
+// previous run left the database dirty
+func (m *Migrator) currentVersion(ctx context.Context, conn *pgxpool.Conn) (uint64, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w: version %d", ErrDirty, version)
+	}
+	if version != 0 && m.index(version) < 0 {
+		return 0, fmt.Errorf("%w: database is at %d", ErrUnknownVersion, version)
+	}
+
+	return version, nil
+}
+
+// rollback runs the down file of version and returns the version below it
+func (m *Migrator) rollback(ctx context.Context, conn *pgxpool.Conn, version uint64) (uint64, error) {
+	i := m.index(version)
+	migration := m.migrations[i]
+	if migration.DownFile == "" {
+		return 0, fmt.Errorf("%w: %d_%s", ErrMissingDownFile, migration.Version, migration.Name)
+	}
+
+	var previous uint64
+	if i > 0 {
+		previous = m.migrations[i-1].Version
+	}
+
+	if err := m.apply(ctx, conn, migration, migration.DownFile, previous); err != nil {
+		return 0, err
+	}
+	return previous, nil
+}
+
+// apply runs file and records version in one transaction, so a failing
+// migration leaves neither partial changes nor a dirty version behind
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, migration Migration, file string, version uint64) error {
+	sql, err := fs.ReadFile(m.fsys, file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		// Without arguments pgx uses the simple protocol, which allows several statements
+		if _, err := tx.Exec(ctx, string(sql)); err != nil {
+			return err
+		}
+		return setVersion(ctx, tx, version)
+	})
+	if err != nil {
+		return fmt.Errorf("migration %s failed: %w", file, err)
+	}
+
+	m.logger.Info("Applied migration", zap.String("file", file))
+	return nil
+}
+
+func (m *Migrator) index(version uint64) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+func ensureVersionTable(ctx context.Context, conn *pgxpool.Conn) error {
+	_, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			dirty BOOLEAN NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// readVersion returns 0 when no migration has been applied
+func readVersion(ctx context.Context, conn *pgxpool.Conn) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return uint64(version), dirty, nil
+}
+
+func setVersion(ctx context.Context, tx pgx.Tx, version uint64) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, int64(version))
+	return err
+}
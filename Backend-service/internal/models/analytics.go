@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// PeriodTotal is the amount raised in one period and campaign currency
+type PeriodTotal struct {
+	Period        time.Time `json:"period"`
+	Currency      string    `json:"currency"`
+	Amount        Money     `json:"amount"`
+	DonationCount int64     `json:"donation_count"`
+	DonorCount    int64     `json:"donor_count"`
+}
+
+// DonorActivity splits the donors active in a period into first-time and returning donors
+type DonorActivity struct {
+	Period          time.Time `json:"period"`
+	NewDonors       int64     `json:"new_donors"`
+	ReturningDonors int64     `json:"returning_donors"`
+}
+
+type CampaignTotal struct {
+	CampaignID    int64  `json:"campaign_id"`
+	Title         string `json:"title"`
+	Currency      string `json:"currency"`
+	Amount        Money  `json:"amount"`
+	DonationCount int64  `json:"donation_count"`
+	DonorCount    int64  `json:"donor_count"`
+}
+
+// DonorConversion counts the users registered in a range and how many of them have donated
+type DonorConversion struct {
+	RegisteredUsers int64 `json:"registered_users"`
+	Donors          int64 `json:"donors"`
+}
+
+// CohortActivity is the number of donors of a cohort who donated again
+// MonthOffset months after their first donation
+type CohortActivity struct {
+	Cohort      time.Time `json:"cohort"`
+	MonthOffset int       `json:"month_offset"`
+	Donors      int64     `json:"donors"`
+}
@@ -4,16 +4,102 @@ import (
 	"time"
 )
 
+const (
+	CampaignStatusDraft         = "draft"
+	CampaignStatusPendingReview = "pending_review"
+	CampaignStatusScheduled     = "scheduled"
+	CampaignStatusActive        = "active"
+	CampaignStatusCompleted     = "completed"
+	CampaignStatusExpired       = "expired"
+	CampaignStatusArchived      = "archived"
+)
+
+// campaignTransitions lists the statuses each campaign status may move to.
+// Recipients submit their drafts for review, and a superadmin publishes them
+// or sends them back to draft. Scheduled campaigns are activated and active
+// campaigns closed by the lifecycle job once their dates are reached.
+var campaignTransitions = map[string][]string{
+	CampaignStatusDraft:         {CampaignStatusPendingReview, CampaignStatusScheduled, CampaignStatusActive, CampaignStatusArchived},
+	CampaignStatusPendingReview: {CampaignStatusDraft, CampaignStatusScheduled, CampaignStatusActive, CampaignStatusArchived},
+	CampaignStatusScheduled:     {CampaignStatusDraft, CampaignStatusActive, CampaignStatusExpired, CampaignStatusArchived},
+	CampaignStatusActive:        {CampaignStatusCompleted, CampaignStatusExpired, CampaignStatusArchived},
+	CampaignStatusCompleted:     {CampaignStatusArchived},
+	CampaignStatusExpired:       {CampaignStatusActive, CampaignStatusArchived},
+}
+
 type Campaigns struct {
-	CampaignID  int64     `json:"campaign_id" db:"campaign_id"`
-	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description" db:"description"`
-	Target      float64   `json:"target_amount" db:"target_amount"`
-	Current     float64   `json:"current_amount" db:"current_amount"`
-	ImageURL    string    `json:"image_url" db:"image_url"`
-	IsActive    bool      `json:"is_active" db:"is_active"`
-	CreatedBy   string    `json:"created_by" db:"created_by"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	ModifiedBy  string    `json:"modified_by" db:"modified_by"`
-	ModifiedAt  time.Time `json:"modified_at" db:"modified_at"`
+	CampaignID       int64     `json:"campaign_id" db:"campaign_id"`
+	Title            string    `json:"title" db:"title"`
+	Description      string    `json:"description" db:"description"`
+	ShortDescription string    `json:"short_description" db:"short_description"`
+	Target           Money     `json:"target_amount" db:"target_amount"`
+	Current          Money     `json:"current_amount" db:"current_amount"`
+	Currency         string    `json:"currency" db:"currency"`
+	ImageURL         string    `json:"image_url" db:"image_url"`
+	StartDate        time.Time `json:"start_date" db:"start_date"`
+	EndDate          time.Time `json:"end_date" db:"end_date"`
+	Status           string    `json:"status" db:"status"`
+	RecipientID      *int64    `json:"recipient_id,omitempty" db:"recipient_id"`
+	CreatedBy        string    `json:"created_by" db:"created_by"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	ModifiedBy       string    `json:"modified_by" db:"modified_by"`
+	ModifiedAt       time.Time `json:"modified_at" db:"modified_at"`
+}
+
+// CanTransitionCampaignStatus reports whether a campaign may move from one status to another
+func CanTransitionCampaignStatus(from, to string) bool {
+	for _, next := range campaignTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOwnedBy reports whether userID is the campaign's recipient
+func (c *Campaigns) IsOwnedBy(userID int64) bool {
+	return c.RecipientID != nil && *c.RecipientID == userID
+}
+
+// IsPublished reports whether the campaign has left draft and is scheduled or running
+func (c *Campaigns) IsPublished() bool {
+	return c.Status == CampaignStatusScheduled || c.Status == CampaignStatusActive
+}
+
+// IsPubliclyVisible reports whether the public may see the campaign and its
+// donors. Drafts, campaigns under review and archived campaigns are hidden.
+func (c *Campaigns) IsPubliclyVisible() bool {
+	switch c.Status {
+	case CampaignStatusDraft, CampaignStatusPendingReview, CampaignStatusArchived:
+		return false
+	}
+	return true
+}
+
+// IsClosed reports whether the campaign has ended for good
+func (c *Campaigns) IsClosed() bool {
+	return c.Status == CampaignStatusCompleted || c.Status == CampaignStatusExpired || c.Status == CampaignStatusArchived
+}
+
+// AcceptsDonations reports whether the campaign is active and within its dates.
+// The dates are checked too so donations stop on time even before the
+// lifecycle job has closed the campaign.
+func (c *Campaigns) AcceptsDonations(now time.Time) bool {
+	return c.Status == CampaignStatusActive && !now.Before(c.StartDate) && now.Before(c.EndDate)
+}
+
+// ScheduledStatus returns the status a published campaign should have at now
+// according to its dates
+func (c *Campaigns) ScheduledStatus(now time.Time) string {
+	switch {
+	case now.Before(c.StartDate):
+		return CampaignStatusScheduled
+	case !now.Before(c.EndDate):
+		if c.Current >= c.Target {
+			return CampaignStatusCompleted
+		}
+		return CampaignStatusExpired
+	default:
+		return CampaignStatusActive
+	}
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// CampaignStats aggregates the paid donations of a campaign, net of refunds
+type CampaignStats struct {
+	TotalRaised            Money `json:"total_raised"`
+	DonorCount             int64 `json:"donor_count"`
+	DonationCount          int64 `json:"donation_count"`
+	AverageGift            Money `json:"average_gift"`
+	MedianGift             Money `json:"median_gift"`
+	AnonymousDonationCount int64 `json:"anonymous_donation_count"`
+	AnonymousAmount        Money `json:"anonymous_amount"`
+}
+
+// DailyTotal is the amount raised on one day
+type DailyTotal struct {
+	Date          time.Time `json:"date"`
+	Amount        Money     `json:"amount"`
+	DonationCount int64     `json:"donation_count"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "testing"
+
+func TestCampaignIsPubliclyVisible(t *testing.T) {
+	visible := map[string]bool{
+		CampaignStatusDraft:         false,
+		CampaignStatusPendingReview: false,
+		CampaignStatusScheduled:     true,
+		CampaignStatusActive:        true,
+		CampaignStatusCompleted:     true,
+		CampaignStatusExpired:       true,
+		CampaignStatusArchived:      false,
+	}
+
+	for status, want := range visible {
+		campaign := &Campaigns{Status: status}
+		if got := campaign.IsPubliclyVisible(); got != want {
+			t.Errorf("IsPubliclyVisible() for %s = %v, want %v", status, got, want)
+		}
+	}
+}
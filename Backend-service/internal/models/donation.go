@@ -4,11 +4,96 @@ import (
 	"time"
 )
 
+const (
+	PaymentStatusPending  = "pending"
+	PaymentStatusPaid     = "paid"
+	PaymentStatusFailed   = "failed"
+	PaymentStatusExpired  = "expired"
+	PaymentStatusRefunded = "refunded"
+)
+
+const (
+	DedicationHonor  = "honor"
+	DedicationMemory = "memory"
+
+	MessageStatusApproved = "approved"
+	MessageStatusRejected = "rejected"
+)
+
+// paymentTransitions lists the statuses each payment status may move to
+var paymentTransitions = map[string][]string{
+	PaymentStatusPending: {PaymentStatusPaid, PaymentStatusFailed, PaymentStatusExpired},
+	PaymentStatusPaid:    {PaymentStatusRefunded},
+}
+
 type Donation struct {
-	ID          int64     `json:"id" db:"id"`
-	UserID      int64     `json:"user_id" db:"user_id"`
-	CampaignID  int64     `json:"campaign_id" db:"campaign_id"`
-	Amount      float64   `json:"amount" db:"amount"`
+	ID                  int64     `json:"id" db:"id"`
+	UserID              int64     `json:"user_id" db:"user_id"`
+	CampaignID          int64     `json:"campaign_id" db:"campaign_id"`
+	Amount              Money     `json:"amount" db:"amount"`
+	Currency            string    `json:"currency" db:"currency"`
+	OriginalAmount      Money     `json:"original_amount" db:"original_amount"`
+	ExchangeRate        string    `json:"exchange_rate" db:"exchange_rate"`
+	IsAnonymous         bool      `json:"is_anonymous" db:"is_anonymous"`
+	PaymentStatus       string    `json:"payment_status" db:"payment_status"`
+	PaymentMethod       string    `json:"payment_method" db:"payment_method"`
+	TransactionID       string    `json:"transaction_id" db:"transaction_id"`
+	Message             string    `json:"message,omitempty" db:"message"`
+	DedicationType      string    `json:"dedication_type,omitempty" db:"dedication_type"`
+	DedicationName      string    `json:"dedication_name,omitempty" db:"dedication_name"`
+	MessageStatus       string    `json:"message_status,omitempty" db:"message_status"`
+	RecurringDonationID *int64    `json:"recurring_donation_id,omitempty" db:"recurring_donation_id"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt          time.Time `json:"modified_at" db:"modified_at"`
+}
+
+// CanTransitionPaymentStatus reports whether a donation may move from one payment status to another
+func CanTransitionPaymentStatus(from, to string) bool {
+	for _, next := range paymentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSettled reports whether the donation counts towards the campaign total
+func (d *Donation) IsSettled() bool {
+	return d.PaymentStatus == PaymentStatusPaid
+}
+
+// HasMessage reports whether the donor left a message or a dedication
+func (d *Donation) HasMessage() bool {
+	return d.Message != "" || d.DedicationName != ""
+}
+
+// SupportMessage is a donor message shown on a campaign's wall of support.
+// DonorName is empty for anonymous donations.
+type SupportMessage struct {
+	DonationID     int64     `json:"donation_id" db:"id"`
+	DonorName      string    `json:"donor_name" db:"donor_name"`
+	IsAnonymous    bool      `json:"is_anonymous" db:"is_anonymous"`
+	Message        string    `json:"message" db:"message"`
+	DedicationType string    `json:"dedication_type" db:"dedication_type"`
+	DedicationName string    `json:"dedication_name" db:"dedication_name"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// PublicDonation is a paid donation as shown in a campaign's public donor
+// feed. DonorName is empty for anonymous donations.
+type PublicDonation struct {
+	DonationID  int64     `json:"donation_id" db:"id"`
+	DonorName   string    `json:"donor_name" db:"donor_name"`
 	IsAnonymous bool      `json:"is_anonymous" db:"is_anonymous"`
+	Amount      Money     `json:"amount" db:"amount"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
+
+// LeaderboardEntry is one donor's total on a campaign leaderboard. A donor's
+// anonymous donations are ranked separately and never carry a name.
+type LeaderboardEntry struct {
+	DonorName     string `json:"donor_name" db:"donor_name"`
+	IsAnonymous   bool   `json:"is_anonymous" db:"is_anonymous"`
+	TotalAmount   Money  `json:"total_amount" db:"total_amount"`
+	DonationCount int    `json:"donation_count" db:"donation_count"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type DonationReceipt struct {
+	ID             int64     `json:"id" db:"id"`
+	DonationID     int64     `json:"donation_id" db:"donation_id"`
+	ReceiptYear    int       `json:"receipt_year" db:"receipt_year"`
+	SequenceNumber int64     `json:"sequence_number" db:"sequence_number"`
+	ReceiptNumber  string    `json:"receipt_number" db:"receipt_number"`
+	IssuedAt       time.Time `json:"issued_at" db:"issued_at"`
+}
+
+// FormatReceiptNumber formats a receipt number such as 2026-000042
+func FormatReceiptNumber(year int, sequence int64) string {
+	return fmt.Sprintf("%d-%06d", year, sequence)
+}
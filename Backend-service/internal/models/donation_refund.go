@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusCompleted = "completed"
+	RefundStatusFailed    = "failed"
+)
+
+// DonationRefund is a reversal entry for part or all of a paid donation.
+// Amount is in the campaign currency, OriginalAmount in the currency the
+// donor paid in. A refund is recorded as pending before the provider is
+// called; afterwards only its status changes. Failed refunds do not count.
+type DonationRefund struct {
+	ID             int64     `json:"id" db:"id"`
+	DonationID     int64     `json:"donation_id" db:"donation_id"`
+	Amount         Money     `json:"amount" db:"amount"`
+	OriginalAmount Money     `json:"original_amount" db:"original_amount"`
+	Currency       string    `json:"currency" db:"currency"`
+	Reason         string    `json:"reason" db:"reason"`
+	Status         string    `json:"status" db:"status"`
+	CreatedBy      string    `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
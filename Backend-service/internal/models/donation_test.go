@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestCanTransitionPaymentStatus(t *testing.T) {
+	statuses := []string{
+		PaymentStatusPending,
+		PaymentStatusPaid,
+		PaymentStatusFailed,
+		PaymentStatusExpired,
+		PaymentStatusRefunded,
+	}
+	allowed := map[[2]string]bool{
+		{PaymentStatusPending, PaymentStatusPaid}:    true,
+		{PaymentStatusPending, PaymentStatusFailed}:  true,
+		{PaymentStatusPending, PaymentStatusExpired}: true,
+		{PaymentStatusPaid, PaymentStatusRefunded}:   true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[[2]string{from, to}]
+			if got := CanTransitionPaymentStatus(from, to); got != want {
+				t.Errorf("CanTransitionPaymentStatus(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	if CanTransitionPaymentStatus("", PaymentStatusPaid) || CanTransitionPaymentStatus(PaymentStatusPending, "unknown") {
+		t.Error("CanTransitionPaymentStatus allowed an unknown status")
+	}
+}
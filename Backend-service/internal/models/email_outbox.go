@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+)
+
+// OutboxEmail is a rendered email waiting for, or done with, delivery. The
+// bodies can carry reset, verification and invitation links, so they are
+// never serialized and are cleared once the email has been sent.
+type OutboxEmail struct {
+	ID            int64      `json:"id" db:"id"`
+	Recipient     string     `json:"recipient" db:"recipient"`
+	Subject       string     `json:"subject" db:"subject"`
+	TextBody      string     `json:"-" db:"text_body"`
+	HTMLBody      string     `json:"-" db:"html_body"`
+	Template      string     `json:"template" db:"template"`
+	Locale        string     `json:"locale" db:"locale"`
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty" db:"last_error"`
+	SentAt        *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	ModifiedAt    time.Time  `json:"modified_at" db:"modified_at"`
+}
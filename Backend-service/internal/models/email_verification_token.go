@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// EmailVerificationToken is stored as the SHA-256 hash of the token mailed to the user
+type EmailVerificationToken struct {
+	ID        int64      `json:"id" db:"id"`
+	UserID    int64      `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+}
+
+// IsUsable reports whether the token can still verify an email at now
+func (t *EmailVerificationToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
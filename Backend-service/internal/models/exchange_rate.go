@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ExchangeRate converts one unit of BaseCurrency into Rate units of QuoteCurrency
+type ExchangeRate struct {
+	ID            int64     `json:"id" db:"id"`
+	BaseCurrency  string    `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency" db:"quote_currency"`
+	Rate          string    `json:"rate" db:"rate"`
+	CreatedBy     string    `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	ModifiedBy    string    `json:"modified_by" db:"modified_by"`
+	ModifiedAt    time.Time `json:"modified_at" db:"modified_at"`
+}
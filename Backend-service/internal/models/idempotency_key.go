@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type IdempotencyKey struct {
+	UserID       int64     `json:"user_id" db:"user_id"`
+	Key          string    `json:"idempotency_key" db:"idempotency_key"`
+	RequestHash  string    `json:"request_hash" db:"request_hash"`
+	StatusCode   *int      `json:"status_code" db:"status_code"`
+	ResponseBody []byte    `json:"response_body" db:"response_body"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// IsCompleted reports whether the first request finished and its response was stored
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.StatusCode != nil
+}
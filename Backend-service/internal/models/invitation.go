@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// Invitation lets a superadmin create a recipient or superadmin account for
+// an email address. The invitee receives a signed link that carries the ID.
+type Invitation struct {
+	ID             int64      `json:"id" db:"id"`
+	Email          string     `json:"email" db:"email"`
+	RoleID         int64      `json:"role_id" db:"role_id"`
+	RoleName       string     `json:"role" db:"role_name"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	AcceptedUserID *int64     `json:"accepted_user_id,omitempty" db:"accepted_user_id"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedBy      string     `json:"revoked_by,omitempty" db:"revoked_by"`
+	CreatedBy      string     `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Status derives the state of the invitation at now
+func (i *Invitation) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationStatusAccepted
+	case i.RevokedAt != nil:
+		return InvitationStatusRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationStatusExpired
+	default:
+		return InvitationStatusPending
+	}
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MoneyScale is the number of decimal places stored in DECIMAL(12, 2) columns
+const MoneyScale = 2
+
+// MaxMoney is the largest value that fits in a DECIMAL(12, 2) column
+const MaxMoney Money = 999_999_999_999
+
+// ErrMoneyOutOfRange is returned when a computed amount does not fit in Money
+var ErrMoneyOutOfRange = errors.New("amount is out of range")
+
+// Money is an amount in minor units (cents). It is stored as DECIMAL(12, 2),
+// and travels over JSON as a decimal string such as "1250.50".
+type Money int64
+
+// ParseMoney parses a plain decimal string with at most two decimal places
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount: empty")
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, frac, hasFrac := strings.Cut(digits, ".")
+	if whole == "" || (hasFrac && frac == "") {
+		return 0, fmt.Errorf("invalid amount: %q", s)
+	}
+	if len(frac) > MoneyScale {
+		return 0, fmt.Errorf("invalid amount %q: at most %d decimal places allowed", s, MoneyScale)
+	}
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("invalid amount: %q", s)
+		}
+	}
+
+	frac += strings.Repeat("0", MoneyScale-len(frac))
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || Money(minor) > MaxMoney {
+		return 0, fmt.Errorf("invalid amount %q: out of range", s)
+	}
+
+	if negative {
+		minor = -minor
+	}
+	return Money(minor), nil
+}
+
+// String formats the amount with exactly two decimal places
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON accepts both "12.50" and 12.50
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// UnmarshalParam lets gin bind form and query values
+func (m *Money) UnmarshalParam(param string) error {
+	parsed, err := ParseMoney(param)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// ScanNumeric implements pgtype.NumericScanner
+func (m *Money) ScanNumeric(v pgtype.Numeric) error {
+	if !v.Valid {
+		*m = 0
+		return nil
+	}
+	if v.NaN || v.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("cannot scan %v into Money", v)
+	}
+
+	n := new(big.Int).Set(v.Int)
+	exp := int64(v.Exp) + MoneyScale
+	if exp >= 0 {
+		n.Mul(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+	} else {
+		var rem big.Int
+		n.QuoRem(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(-exp), nil), &rem)
+		if rem.Sign() != 0 {
+			return fmt.Errorf("cannot scan numeric with more than %d decimal places into Money", MoneyScale)
+		}
+	}
+	if !n.IsInt64() {
+		return fmt.Errorf("numeric value out of range for Money")
+	}
+
+	*m = Money(n.Int64())
+	return nil
+}
+
+// NumericValue implements pgtype.NumericValuer
+func (m Money) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(m)), Exp: -MoneyScale, Valid: true}, nil
+}
+
+// Convert multiplies the amount by rate, rounding half away from zero to whole
+// minor units. Results beyond MaxMoney return ErrMoneyOutOfRange.
+func (m Money) Convert(rate *big.Rat) (Money, error) {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), rate)
+
+	quo, rem := new(big.Int).QuoRem(product.Num(), product.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(product.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(product.Sign())))
+	}
+
+	if !quo.IsInt64() {
+		return 0, ErrMoneyOutOfRange
+	}
+	converted := Money(quo.Int64())
+	if converted > MaxMoney || converted < -MaxMoney {
+		return 0, ErrMoneyOutOfRange
+	}
+	return converted, nil
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{in: "0", want: 0},
+		{in: "12", want: 1200},
+		{in: "12.5", want: 1250},
+		{in: "12.50", want: 1250},
+		{in: " 0.01 ", want: 1},
+		{in: "+3.10", want: 310},
+		{in: "-12.34", want: -1234},
+		{in: "9999999999.99", want: MaxMoney},
+		{in: "-9999999999.99", want: -MaxMoney},
+		{in: "", wantErr: true},
+		{in: "1.234", wantErr: true},
+		{in: "0.001", wantErr: true},
+		{in: "10000000000.00", wantErr: true},
+		{in: "99999999999999999999", wantErr: true},
+		{in: ".50", wantErr: true},
+		{in: "12.", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "1,000.00", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMoney(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want string
+	}{
+		{0, `"0.00"`},
+		{1, `"0.01"`},
+		{1250, `"12.50"`},
+		{-5, `"-0.05"`},
+		{MaxMoney, `"9999999999.99"`},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.in)
+		if err != nil {
+			t.Errorf("Marshal(%d) error = %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%d) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{in: `"12.50"`, want: 1250},
+		{in: `12.50`, want: 1250},
+		{in: `12.5`, want: 1250},
+		{in: `100`, want: 10000},
+		{in: `"-1.00"`, want: -100},
+		{in: `null`, want: 0},
+		{in: `12.345`, wantErr: true},
+		{in: `"12.345"`, wantErr: true},
+		{in: `1e2`, wantErr: true},
+		{in: `"10000000000"`, wantErr: true},
+		{in: `true`, wantErr: true},
+		{in: `""`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var got Money
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyScanNumeric(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      pgtype.Numeric
+		want    Money
+		wantErr bool
+	}{
+		{name: "two decimals", in: pgtype.Numeric{Int: big.NewInt(1250), Exp: -2, Valid: true}, want: 1250},
+		{name: "one decimal", in: pgtype.Numeric{Int: big.NewInt(125), Exp: -1, Valid: true}, want: 1250},
+		{name: "whole number", in: pgtype.Numeric{Int: big.NewInt(12), Exp: 0, Valid: true}, want: 1200},
+		{name: "positive exponent", in: pgtype.Numeric{Int: big.NewInt(12), Exp: 3, Valid: true}, want: 1_200_000},
+		{name: "trailing zeros", in: pgtype.Numeric{Int: big.NewInt(12500), Exp: -3, Valid: true}, want: 1250},
+		{name: "negative", in: pgtype.Numeric{Int: big.NewInt(-1250), Exp: -2, Valid: true}, want: -1250},
+		{name: "null", in: pgtype.Numeric{}, want: 0},
+		{name: "three decimals", in: pgtype.Numeric{Int: big.NewInt(12345), Exp: -3, Valid: true}, wantErr: true},
+		{name: "NaN", in: pgtype.Numeric{NaN: true, Valid: true}, wantErr: true},
+		{name: "infinity", in: pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, wantErr: true},
+		{name: "out of range", in: pgtype.Numeric{Int: big.NewInt(1), Exp: 30, Valid: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got := Money(42)
+		err := got.ScanNumeric(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ScanNumeric() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%s: ScanNumeric() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyNumericValue(t *testing.T) {
+	for _, m := range []Money{0, 1, 1250, -1250, MaxMoney} {
+		v, err := m.NumericValue()
+		if err != nil {
+			t.Errorf("NumericValue(%d) error = %v", m, err)
+			continue
+		}
+		if !v.Valid || v.Exp != -MoneyScale || v.Int.Int64() != int64(m) {
+			t.Errorf("NumericValue(%d) = %+v", m, v)
+		}
+
+		var back Money
+		if err := back.ScanNumeric(v); err != nil || back != m {
+			t.Errorf("ScanNumeric(NumericValue(%d)) = %d, %v", m, back, err)
+		}
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	tests := []struct {
+		amount  Money
+		rate    string
+		want    Money
+		wantErr bool
+	}{
+		{1000, "1", 1000, false},
+		{1000, "0.5", 500, false},
+		{1, "0.5", 1, false},
+		{1, "0.49", 0, false},
+		{3, "0.5", 2, false},
+		{-1, "0.5", -1, false},
+		{-3, "0.5", -2, false},
+		{100, "1/3", 33, false},
+		{200, "1/3", 67, false},
+		{10000, "16250.5", 162_505_000, false},
+		{1_500_000_000, "0.0000615", 92250, false},
+		{MaxMoney, "1", MaxMoney, false},
+		{-MaxMoney, "1", -MaxMoney, false},
+		{MaxMoney, "1.01", 0, true},
+		{-MaxMoney, "2", 0, true},
+		{MaxMoney, "16250", 0, true},
+		{MaxMoney, "100000000000", 0, true},
+	}
+
+	for _, tt := range tests {
+		rate, ok := new(big.Rat).SetString(tt.rate)
+		if !ok {
+			t.Fatalf("invalid rate %q", tt.rate)
+		}
+		got, err := tt.amount.Convert(rate)
+		if tt.wantErr {
+			if !errors.Is(err, ErrMoneyOutOfRange) {
+				t.Errorf("Money(%d).Convert(%s) error = %v, want %v", tt.amount, tt.rate, err, ErrMoneyOutOfRange)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Money(%d).Convert(%s) = %d, %v, want %d", tt.amount, tt.rate, got, err, tt.want)
+		}
+	}
+}
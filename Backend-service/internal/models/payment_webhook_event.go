@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PaymentWebhookEvent struct {
+	ID              int64           `json:"id" db:"id"`
+	Provider        string          `json:"provider" db:"provider"`
+	EventID         string          `json:"event_id" db:"event_id"`
+	TransactionID   string          `json:"transaction_id" db:"transaction_id"`
+	Status          string          `json:"status" db:"status"`
+	Payload         json.RawMessage `json:"payload" db:"payload"`
+	Signature       string          `json:"signature" db:"signature"`
+	Processed       bool            `json:"processed" db:"processed"`
+	ProcessingError string          `json:"processing_error,omitempty" db:"processing_error"`
+	ReceivedAt      time.Time       `json:"received_at" db:"received_at"`
+	ProcessedAt     *time.Time      `json:"processed_at,omitempty" db:"processed_at"`
+}
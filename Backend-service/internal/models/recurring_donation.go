@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	RecurringStatusActive    = "active"
+	RecurringStatusPaused    = "paused"
+	RecurringStatusCancelled = "cancelled"
+
+	RecurringIntervalWeekly  = "weekly"
+	RecurringIntervalMonthly = "monthly"
+	RecurringIntervalYearly  = "yearly"
+)
+
+type RecurringDonation struct {
+	ID             int64      `json:"id" db:"id"`
+	UserID         int64      `json:"user_id" db:"user_id"`
+	CampaignID     int64      `json:"campaign_id" db:"campaign_id"`
+	Amount         Money      `json:"amount" db:"amount"`
+	Currency       string     `json:"currency" db:"currency"`
+	IsAnonymous    bool       `json:"is_anonymous" db:"is_anonymous"`
+	PaymentMethod  string     `json:"payment_method" db:"payment_method"`
+	Interval       string     `json:"interval" db:"interval"`
+	AnchorDay      int        `json:"anchor_day" db:"anchor_day"`
+	NextChargeDate time.Time  `json:"next_charge_date" db:"next_charge_date"`
+	LastChargedAt  *time.Time `json:"last_charged_at,omitempty" db:"last_charged_at"`
+	Status         string     `json:"status" db:"status"`
+	CreatedBy      string     `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	ModifiedBy     string     `json:"modified_by" db:"modified_by"`
+	ModifiedAt     time.Time  `json:"modified_at" db:"modified_at"`
+}
+
+// NextCharge returns the charge date that follows from. Monthly and yearly
+// schedules keep to AnchorDay, clamped to the last day of shorter months.
+func (r *RecurringDonation) NextCharge(from time.Time) time.Time {
+	switch r.Interval {
+	case RecurringIntervalWeekly:
+		return from.AddDate(0, 0, 7)
+	case RecurringIntervalYearly:
+		return anchoredDate(from.Year()+1, from.Month(), r.AnchorDay, from)
+	default:
+		return anchoredDate(from.Year(), from.Month()+1, r.AnchorDay, from)
+	}
+}
+
+func anchoredDate(year int, month time.Month, day int, clock time.Time) time.Time {
+	// Day 0 of the following month is the last day of this one
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, clock.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), 0, clock.Location())
+}
@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// Role names seeded by the role table migration
+const (
+	RoleSuperadmin = "superadmin"
+	RoleDonor      = "donor"
+	RoleRecipient  = "recipient"
+)
+
 type Role struct {
 	RoleID          int64     `json:"role_id" db:"role_id"`
 	RoleName        string    `json:"role_name" db:"role_name"`
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedByUser         = "revoked"
+	SessionRevokedTokenReuse     = "token_reuse"
+	SessionRevokedPasswordChange = "password_change"
+	SessionRevokedDeactivated    = "user_deactivated"
+)
+
+// UserSession is one signed-in device, identified by its refresh token family
+type UserSession struct {
+	ID           int64      `json:"id" db:"id"`
+	UserID       int64      `json:"user_id" db:"user_id"`
+	UserAgent    string     `json:"user_agent" db:"user_agent"`
+	IPAddress    string     `json:"ip_address" db:"ip_address"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt   time.Time  `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokeReason string     `json:"revoke_reason,omitempty" db:"revoke_reason"`
+}
+
+// IsActive reports whether the session can still be refreshed at now
+func (s *UserSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken is stored as the SHA-256 hash of the opaque token handed to the client
+type RefreshToken struct {
+	ID        int64      `json:"id" db:"id"`
+	SessionID int64      `json:"session_id" db:"session_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+}
+
+// IsUsed reports whether the token was already exchanged for a new one
+func (t *RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
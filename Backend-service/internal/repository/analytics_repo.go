@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AnalyticsRepositoryInterface interface {
+	GetPeriodTotals(ctx context.Context, from, to time.Time, campaignID *int64, interval string) ([]models.PeriodTotal, error)
+	GetDonorActivity(ctx context.Context, from, to time.Time, campaignID *int64, interval string) ([]models.DonorActivity, error)
+	GetTopCampaigns(ctx context.Context, from, to time.Time, campaignID *int64, limit int) ([]models.CampaignTotal, error)
+	GetDonorConversion(ctx context.Context, from, to time.Time, campaignID *int64) (*models.DonorConversion, error)
+	GetRetentionCohorts(ctx context.Context, from, to time.Time, campaignID *int64) ([]models.CohortActivity, error)
+}
+
+// AnalyticsRepository runs the aggregate queries behind the CMS analytics
+// endpoints. Every query takes a [from, to) range and an optional campaign.
+type AnalyticsRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewAnalyticsRepository(db *pgxpool.Pool, schema string) *AnalyticsRepository {
+	return &AnalyticsRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+// analyticsDonations selects the paid donations in range with refunds taken off
+const analyticsDonations = `
+	SELECT d.id, d.user_id, d.campaign_id, d.created_at,
+		d.amount - COALESCE((SELECT SUM(dr.amount) FROM donation_refunds dr WHERE dr.donation_id = d.id AND dr.status <> 'failed'), 0) AS net_amount
+	FROM donations d
+	WHERE d.payment_status = 'paid'
+		AND d.created_at >= $1 AND d.created_at < $2
+		AND ($3::bigint IS NULL OR d.campaign_id = $3)
+`
+
+// firstDonations gives every donor's first paid donation, within the campaign when one is given
+const firstDonations = `
+	SELECT d.user_id, MIN(d.created_at) AS first_at
+	FROM donations d
+	WHERE d.payment_status = 'paid' AND ($3::bigint IS NULL OR d.campaign_id = $3)
+	GROUP BY d.user_id
+`
+
+// GetPeriodTotals sums donations per day, week or month and campaign currency
+func (r *AnalyticsRepository) GetPeriodTotals(ctx context.Context, from, to time.Time, campaignID *int64, interval string) ([]models.PeriodTotal, error) {
+	query := `
+		WITH n AS (` + analyticsDonations + `)
+		SELECT date_trunc($4::text, n.created_at), c.currency, SUM(n.net_amount), COUNT(*), COUNT(DISTINCT n.user_id)
+		FROM n
+		JOIN campaigns c ON c.campaign_id = n.campaign_id
+		WHERE n.net_amount > 0
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, from, to, campaignID, interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []models.PeriodTotal{}
+	for rows.Next() {
+		var item models.PeriodTotal
+		if err := rows.Scan(&item.Period, &item.Currency, &item.Amount, &item.DonationCount, &item.DonorCount); err != nil {
+			return nil, err
+		}
+		totals = append(totals, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// GetDonorActivity counts, per period, the donors whose first donation fell
+// in that period and the donors who had given before
+func (r *AnalyticsRepository) GetDonorActivity(ctx context.Context, from, to time.Time, campaignID *int64, interval string) ([]models.DonorActivity, error) {
+	query := `
+		WITH n AS (` + analyticsDonations + `),
+		f AS (` + firstDonations + `),
+		active AS (
+			SELECT DISTINCT date_trunc($4::text, n.created_at) AS period, n.user_id
+			FROM n
+			WHERE n.net_amount > 0
+		)
+		SELECT
+			a.period,
+			COUNT(*) FILTER (WHERE date_trunc($4::text, f.first_at) = a.period),
+			COUNT(*) FILTER (WHERE date_trunc($4::text, f.first_at) < a.period)
+		FROM active a
+		JOIN f ON f.user_id = a.user_id
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, from, to, campaignID, interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := []models.DonorActivity{}
+	for rows.Next() {
+		var item models.DonorActivity
+		if err := rows.Scan(&item.Period, &item.NewDonors, &item.ReturningDonors); err != nil {
+			return nil, err
+		}
+		activity = append(activity, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
+
+// GetTopCampaigns ranks campaigns by the amount raised in range. Amounts are
+// in each campaign's own currency.
+func (r *AnalyticsRepository) GetTopCampaigns(ctx context.Context, from, to time.Time, campaignID *int64, limit int) ([]models.CampaignTotal, error) {
+	query := `
+		WITH n AS (` + analyticsDonations + `)
+		SELECT c.campaign_id, c.title, c.currency, SUM(n.net_amount), COUNT(*), COUNT(DISTINCT n.user_id)
+		FROM n
+		JOIN campaigns c ON c.campaign_id = n.campaign_id
+		WHERE n.net_amount > 0
+		GROUP BY c.campaign_id, c.title, c.currency
+		ORDER BY SUM(n.net_amount) DESC, c.campaign_id
+		LIMIT $4
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, from, to, campaignID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaigns := []models.CampaignTotal{}
+	for rows.Next() {
+		var item models.CampaignTotal
+		if err := rows.Scan(&item.CampaignID, &item.Title, &item.Currency, &item.Amount, &item.DonationCount, &item.DonorCount); err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return campaigns, nil
+}
+
+// GetDonorConversion counts the users who registered in range and how many
+// of them have made a paid donation since
+func (r *AnalyticsRepository) GetDonorConversion(ctx context.Context, from, to time.Time, campaignID *int64) (*models.DonorConversion, error) {
+	query := `
+		WITH f AS (` + firstDonations + `)
+		SELECT COUNT(*), COUNT(f.user_id)
+		FROM users u
+		LEFT JOIN f ON f.user_id = u.user_id
+		WHERE u.created_at >= $1 AND u.created_at < $2
+	`
+
+	var conversion models.DonorConversion
+	err := conn(ctx, r.db).QueryRow(ctx, query, from, to, campaignID).Scan(&conversion.RegisteredUsers, &conversion.Donors)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversion, nil
+}
+
+// GetRetentionCohorts groups donors by the month of their first donation,
+// for cohorts starting in range, and counts how many of them donated again
+// in each following month
+func (r *AnalyticsRepository) GetRetentionCohorts(ctx context.Context, from, to time.Time, campaignID *int64) ([]models.CohortActivity, error) {
+	query := `
+		WITH f AS (` + firstDonations + `),
+		cohorts AS (
+			SELECT f.user_id, date_trunc('month', f.first_at) AS cohort
+			FROM f
+			WHERE f.first_at >= date_trunc('month', $1::timestamptz) AND f.first_at < $2
+		),
+		activity AS (
+			SELECT DISTINCT d.user_id, date_trunc('month', d.created_at) AS month
+			FROM donations d
+			WHERE d.payment_status = 'paid' AND ($3::bigint IS NULL OR d.campaign_id = $3)
+		)
+		SELECT
+			c.cohort,
+			((EXTRACT(YEAR FROM a.month) - EXTRACT(YEAR FROM c.cohort)) * 12
+				+ EXTRACT(MONTH FROM a.month) - EXTRACT(MONTH FROM c.cohort))::int,
+			COUNT(*)
+		FROM cohorts c
+		JOIN activity a ON a.user_id = c.user_id
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, from, to, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cohorts := []models.CohortActivity{}
+	for rows.Next() {
+		var item models.CohortActivity
+		if err := rows.Scan(&item.Cohort, &item.MonthOffset, &item.Donors); err != nil {
+			return nil, err
+		}
+		cohorts = append(cohorts, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cohorts, nil
+}
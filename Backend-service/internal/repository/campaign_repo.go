@@ -2,7 +2,7 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"share-the-meal/internal/models"
 	"time"
 
@@ -10,12 +10,22 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrCampaignNotFound is returned when no campaign has the requested ID
+var ErrCampaignNotFound = errors.New("campaign not found")
+
 type CampaignRepositoryInterface interface {
 	CreateCampaign(ctx context.Context, campaign *models.Campaigns) error
 	GetCampaignByID(ctx context.Context, id int64) (*models.Campaigns, error)
 	UpdateCampaign(ctx context.Context, campaign *models.Campaigns) error
+	UpdateStatus(ctx context.Context, id int64, status string, modifiedBy string) error
 	DeleteCampaign(ctx context.Context, id int64) error
 	ListActiveCampaigns(ctx context.Context) ([]models.Campaigns, error)
+	ListCampaigns(ctx context.Context, status string) ([]models.Campaigns, error)
+	ListRecipientCampaigns(ctx context.Context, recipientID int64) ([]models.Campaigns, error)
+	IncrementCurrentAmount(ctx context.Context, id int64, amount models.Money) error
+	ActivateScheduledCampaigns(ctx context.Context, now time.Time) (int64, error)
+	CloseEndedCampaigns(ctx context.Context, now time.Time) (int64, error)
+	RecomputeCurrentAmounts(ctx context.Context, campaignID *int64) (int64, error)
 }
 
 type CampaignRepository struct {
@@ -30,48 +40,67 @@ func NewCampaignRepository(db *pgxpool.Pool, schema string) *CampaignRepository
 	}
 }
 
+const campaignColumns = `
+	campaign_id, title, description, COALESCE(short_description, ''), target_amount, current_amount, currency,
+	COALESCE(image_url, ''), start_date, end_date, status, recipient_id,
+	COALESCE(created_by, ''), created_at, COALESCE(modified_by, ''), modified_at
+`
+
+func scanCampaign(row pgx.Row, campaign *models.Campaigns) error {
+	return row.Scan(
+		&campaign.CampaignID,
+		&campaign.Title,
+		&campaign.Description,
+		&campaign.ShortDescription,
+		&campaign.Target,
+		&campaign.Current,
+		&campaign.Currency,
+		&campaign.ImageURL,
+		&campaign.StartDate,
+		&campaign.EndDate,
+		&campaign.Status,
+		&campaign.RecipientID,
+		&campaign.CreatedBy,
+		&campaign.CreatedAt,
+		&campaign.ModifiedBy,
+		&campaign.ModifiedAt,
+	)
+}
+
 func (r *CampaignRepository) CreateCampaign(ctx context.Context, campaign *models.Campaigns) error {
 	query := `
-		INSERT INTO campaigns (title, description, target_amount, current_amount, image_url, is_active, created_at, modified_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id
-	`
+		INSERT INTO campaigns (
+			title, description, short_description, target_amount, current_amount, currency, image_url,
+			start_date, end_date, status, recipient_id, created_by, created_at, modified_by, modified_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $12, $13)
+		RETURNING ` + campaignColumns
 
-	return r.db.QueryRow(ctx, query,
+	return scanCampaign(conn(ctx, r.db).QueryRow(ctx, query,
 		campaign.Title,
 		campaign.Description,
+		campaign.ShortDescription,
 		campaign.Target,
 		campaign.Current,
+		campaign.Currency,
 		campaign.ImageURL,
-		campaign.IsActive,
-		time.Now(),
+		campaign.StartDate,
+		campaign.EndDate,
+		campaign.Status,
+		campaign.RecipientID,
+		campaign.CreatedBy,
 		time.Now(),
-	).Scan(&campaign.CampaignID)
+	), campaign)
 }
 
 func (r *CampaignRepository) GetCampaignByID(ctx context.Context, id int64) (*models.Campaigns, error) {
-	query := `
-		SELECT id, title, description,  target_amount, current_amount, image_url, is_active, created_at, modified_at
-		FROM campaigns
-		WHERE id = $1
-	`
+	query := `SELECT ` + campaignColumns + ` FROM campaigns WHERE campaign_id = $1`
 
 	var campaign models.Campaigns
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&campaign.CampaignID,
-		&campaign.Title,
-		&campaign.Description,
-		&campaign.Target,
-		&campaign.Current,
-		&campaign.ImageURL,
-		&campaign.IsActive,
-		&campaign.CreatedAt,
-		&campaign.ModifiedAt,
-	)
-
+	err := scanCampaign(conn(ctx, r.db).QueryRow(ctx, query, id), &campaign)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("campaign not found")
+			return nil, ErrCampaignNotFound
 		}
 		return nil, err
 	}
@@ -84,41 +113,108 @@ func (r *CampaignRepository) UpdateCampaign(ctx context.Context, campaign *model
         UPDATE campaigns 
         SET title = $1, 
             description = $2, 
-            target_amount = $3, 
-            current_amount = $4, 
-            image_url = $5, 
-            is_active = $6,
-            modified_at = $7
-        WHERE id = $8
+            short_description = $3,
+            target_amount = $4, 
+            currency = $5,
+            image_url = $6, 
+            start_date = $7,
+            end_date = $8,
+            status = $9,
+            recipient_id = $10,
+            modified_by = $11,
+            modified_at = $12
+        WHERE campaign_id = $13
     `
 
-	_, err := r.db.Exec(ctx, query,
+	_, err := conn(ctx, r.db).Exec(ctx, query,
 		campaign.Title,
 		campaign.Description,
+		campaign.ShortDescription,
 		campaign.Target,
-		campaign.Current,
+		campaign.Currency,
 		campaign.ImageURL,
-		campaign.IsActive,
+		campaign.StartDate,
+		campaign.EndDate,
+		campaign.Status,
+		campaign.RecipientID,
+		campaign.ModifiedBy,
 		time.Now(),
 		campaign.CampaignID,
 	)
 	return err
 }
 
+func (r *CampaignRepository) UpdateStatus(ctx context.Context, id int64, status string, modifiedBy string) error {
+	query := `
+		UPDATE campaigns
+		SET status = $1,
+			modified_by = $2,
+			modified_at = $3
+		WHERE campaign_id = $4
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, status, modifiedBy, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCampaignNotFound
+	}
+
+	return nil
+}
+
+// IncrementCurrentAmount adds amount to current_amount in SQL so concurrent
+// donations never overwrite each other. current_amount is only changed here,
+// UpdateCampaign leaves it alone.
+func (r *CampaignRepository) IncrementCurrentAmount(ctx context.Context, id int64, amount models.Money) error {
+	query := `
+		UPDATE campaigns
+		SET current_amount = current_amount + $1,
+			modified_at = $2
+		WHERE campaign_id = $3
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, amount, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCampaignNotFound
+	}
+
+	return nil
+}
+
 func (r *CampaignRepository) DeleteCampaign(ctx context.Context, id int64) error {
-	query := `DELETE FROM campaigns WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
+	query := `DELETE FROM campaigns WHERE campaign_id = $1`
+	_, err := conn(ctx, r.db).Exec(ctx, query, id)
 	return err
 }
 
 func (r *CampaignRepository) ListActiveCampaigns(ctx context.Context) ([]models.Campaigns, error) {
+	return r.listCampaigns(ctx, models.CampaignStatusActive, nil)
+}
+
+// ListCampaigns lists campaigns in any status, or only those in status when it is not empty
+func (r *CampaignRepository) ListCampaigns(ctx context.Context, status string) ([]models.Campaigns, error) {
+	return r.listCampaigns(ctx, status, nil)
+}
+
+// ListRecipientCampaigns lists the campaigns of one recipient in every status
+func (r *CampaignRepository) ListRecipientCampaigns(ctx context.Context, recipientID int64) ([]models.Campaigns, error) {
+	return r.listCampaigns(ctx, "", &recipientID)
+}
+
+func (r *CampaignRepository) listCampaigns(ctx context.Context, status string, recipientID *int64) ([]models.Campaigns, error) {
 	query := `
-        SELECT id, title, description,  target_amount, current_amount, image_url, is_active, created_at, modified_at
-        FROM campaigns
-        WHERE is_active = true
-    `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE ($1 = '' OR status = $1) AND ($2::bigint IS NULL OR recipient_id = $2)
+		ORDER BY created_at DESC, campaign_id DESC
+	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := conn(ctx, r.db).Query(ctx, query, status, recipientID)
 	if err != nil {
 		return nil, err
 	}
@@ -127,24 +223,82 @@ func (r *CampaignRepository) ListActiveCampaigns(ctx context.Context) ([]models.
 	var campaigns []models.Campaigns
 	for rows.Next() {
 		var c models.Campaigns
-		err := rows.Scan(
-			&c.CampaignID,
-			&c.Title,
-			&c.Description,
-			&c.Target,
-			&c.Current,
-			&c.ImageURL,
-			&c.IsActive,
-			&c.CreatedAt,
-			&c.ModifiedAt,
-		)
-		if err != nil {
+		if err := scanCampaign(rows, &c); err != nil {
 			return nil, err
 		}
 		campaigns = append(campaigns, c)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return campaigns, nil
 }
 
-// Implement other methods: UpdateCampaign, DeleteCampaign, ListActiveCampaigns
+// ActivateScheduledCampaigns opens the scheduled campaigns whose start date has been reached
+func (r *CampaignRepository) ActivateScheduledCampaigns(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE campaigns
+		SET status = 'active',
+			modified_by = 'system',
+			modified_at = $1
+		WHERE status = 'scheduled' AND start_date <= $1 AND end_date > $1
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// CloseEndedCampaigns closes the open campaigns whose end date has passed.
+// Campaigns that reached their target are completed, the others expire.
+func (r *CampaignRepository) CloseEndedCampaigns(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE campaigns
+		SET status = CASE WHEN current_amount >= target_amount THEN 'completed' ELSE 'expired' END,
+			modified_by = 'system',
+			modified_at = $1
+		WHERE status IN ('scheduled', 'active') AND end_date <= $1
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// RecomputeCurrentAmounts rebuilds current_amount from the paid donations net
+// of refunds, for one campaign or all of them when campaignID is nil. It
+// returns how many campaigns had a drifted total.
+func (r *CampaignRepository) RecomputeCurrentAmounts(ctx context.Context, campaignID *int64) (int64, error) {
+	query := `
+		WITH totals AS (
+			SELECT c.campaign_id, COALESCE(SUM(
+				d.amount - COALESCE((SELECT SUM(dr.amount) FROM donation_refunds dr WHERE dr.donation_id = d.id AND dr.status <> 'failed'), 0)
+			), 0) AS total
+			FROM campaigns c
+			LEFT JOIN donations d ON d.campaign_id = c.campaign_id AND d.payment_status = 'paid'
+			WHERE ($1::bigint IS NULL OR c.campaign_id = $1)
+			GROUP BY c.campaign_id
+		)
+		UPDATE campaigns c
+		SET current_amount = t.total,
+			modified_by = 'system',
+			modified_at = $2
+		FROM totals t
+		WHERE c.campaign_id = t.campaign_id AND c.current_amount <> t.total
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, campaignID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DonationReceiptRepositoryInterface interface {
+	GetReceiptByDonationID(ctx context.Context, donationID int64) (*models.DonationReceipt, error)
+	NextSequenceNumber(ctx context.Context, year int) (int64, error)
+	CreateReceipt(ctx context.Context, receipt *models.DonationReceipt) error
+}
+
+type DonationReceiptRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewDonationReceiptRepository(db *pgxpool.Pool, schema string) *DonationReceiptRepository {
+	return &DonationReceiptRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+func (r *DonationReceiptRepository) GetReceiptByDonationID(ctx context.Context, donationID int64) (*models.DonationReceipt, error) {
+	query := `
+		SELECT id, donation_id, receipt_year, sequence_number, receipt_number, issued_at
+		FROM donation_receipts
+		WHERE donation_id = $1
+	`
+
+	var receipt models.DonationReceipt
+	err := conn(ctx, r.db).QueryRow(ctx, query, donationID).Scan(
+		&receipt.ID,
+		&receipt.DonationID,
+		&receipt.ReceiptYear,
+		&receipt.SequenceNumber,
+		&receipt.ReceiptNumber,
+		&receipt.IssuedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// NextSequenceNumber increments the counter for year and returns the new
+// value. The counter row stays locked until the transaction ends, so
+// concurrent receipts are numbered one after another. Must run inside a
+// transaction.
+func (r *DonationReceiptRepository) NextSequenceNumber(ctx context.Context, year int) (int64, error) {
+	query := `
+		INSERT INTO receipt_sequences (receipt_year, last_number)
+		VALUES ($1, 1)
+		ON CONFLICT (receipt_year) DO UPDATE
+		SET last_number = receipt_sequences.last_number + 1
+		RETURNING last_number
+	`
+
+	var sequence int64
+	err := conn(ctx, r.db).QueryRow(ctx, query, year).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *DonationReceiptRepository) CreateReceipt(ctx context.Context, receipt *models.DonationReceipt) error {
+	query := `
+		INSERT INTO donation_receipts (donation_id, receipt_year, sequence_number, receipt_number, issued_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, issued_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		receipt.DonationID,
+		receipt.ReceiptYear,
+		receipt.SequenceNumber,
+		receipt.ReceiptNumber,
+		time.Now(),
+	).Scan(&receipt.ID, &receipt.IssuedAt)
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DonationRefundRepositoryInterface interface {
+	CreateRefund(ctx context.Context, refund *models.DonationRefund) error
+	GetDonationRefunds(ctx context.Context, donationID int64) ([]models.DonationRefund, error)
+	GetRefundedTotals(ctx context.Context, donationID int64) (amount, originalAmount models.Money, err error)
+	GetUserRefundedTotals(ctx context.Context, userID int64) (map[int64]models.Money, error)
+	UpdateRefundStatus(ctx context.Context, id int64, fromStatus, toStatus string) error
+}
+
+type DonationRefundRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewDonationRefundRepository(db *pgxpool.Pool, schema string) *DonationRefundRepository {
+	return &DonationRefundRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+const donationRefundColumns = `
+	id, donation_id, amount, original_amount, currency, COALESCE(reason, ''), status,
+	COALESCE(created_by, ''), created_at
+`
+
+func scanDonationRefund(row pgx.Row, refund *models.DonationRefund) error {
+	return row.Scan(
+		&refund.ID,
+		&refund.DonationID,
+		&refund.Amount,
+		&refund.OriginalAmount,
+		&refund.Currency,
+		&refund.Reason,
+		&refund.Status,
+		&refund.CreatedBy,
+		&refund.CreatedAt,
+	)
+}
+
+func (r *DonationRefundRepository) CreateRefund(ctx context.Context, refund *models.DonationRefund) error {
+	query := `
+		INSERT INTO donation_refunds (donation_id, amount, original_amount, currency, reason, status, created_by, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		refund.DonationID,
+		refund.Amount,
+		refund.OriginalAmount,
+		refund.Currency,
+		refund.Reason,
+		refund.Status,
+		refund.CreatedBy,
+		time.Now(),
+	).Scan(&refund.ID, &refund.CreatedAt)
+}
+
+func (r *DonationRefundRepository) GetDonationRefunds(ctx context.Context, donationID int64) ([]models.DonationRefund, error) {
+	query := `SELECT ` + donationRefundColumns + ` FROM donation_refunds WHERE donation_id = $1 ORDER BY created_at`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, donationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []models.DonationRefund
+	for rows.Next() {
+		var refund models.DonationRefund
+		if err := scanDonationRefund(rows, &refund); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// GetRefundedTotals returns how much of a donation has been refunded, in the
+// campaign currency and in the currency the donor paid in. Pending refunds
+// count, so a concurrent refund can never exceed the donation.
+func (r *DonationRefundRepository) GetRefundedTotals(ctx context.Context, donationID int64) (amount, originalAmount models.Money, err error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0), COALESCE(SUM(original_amount), 0)
+		FROM donation_refunds
+		WHERE donation_id = $1 AND status <> 'failed'
+	`
+
+	err = conn(ctx, r.db).QueryRow(ctx, query, donationID).Scan(&amount, &originalAmount)
+	return amount, originalAmount, err
+}
+
+// GetUserRefundedTotals returns, per donation of userID that has refunds, the
+// refunded amount in the currency the donor paid in
+func (r *DonationRefundRepository) GetUserRefundedTotals(ctx context.Context, userID int64) (map[int64]models.Money, error) {
+	query := `
+		SELECT dr.donation_id, SUM(dr.original_amount)
+		FROM donation_refunds dr
+		JOIN donations d ON d.id = dr.donation_id
+		WHERE d.user_id = $1 AND dr.status <> 'failed'
+		GROUP BY dr.donation_id
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[int64]models.Money)
+	for rows.Next() {
+		var donationID int64
+		var refunded models.Money
+		if err := rows.Scan(&donationID, &refunded); err != nil {
+			return nil, err
+		}
+		totals[donationID] = refunded
+	}
+
+	return totals, rows.Err()
+}
+
+// UpdateRefundStatus moves a refund from fromStatus to toStatus
+func (r *DonationRefundRepository) UpdateRefundStatus(ctx context.Context, id int64, fromStatus, toStatus string) error {
+	query := `UPDATE donation_refunds SET status = $1 WHERE id = $2 AND status = $3`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, toStatus, id, fromStatus)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("refund %d is not %s", id, fromStatus)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"share-the-meal/internal/models"
 	"time"
 
@@ -12,9 +13,20 @@ import (
 type DonationRepositoryInterface interface {
 	CreateDonation(ctx context.Context, donation *models.Donation) error
 	GetDonationByID(ctx context.Context, id int64) (*models.Donation, error)
+	LockDonation(ctx context.Context, id int64) (*models.Donation, error)
+	GetDonationByTransactionID(ctx context.Context, transactionID string) (*models.Donation, error)
 	GetUserDonations(ctx context.Context, userID int64) ([]models.Donation, error)
 	GetCampaignDonations(ctx context.Context, campaignID int64) ([]models.Donation, error)
 	GetAllDonations(ctx context.Context) ([]models.Donation, error)
+	GetDonorIDsByYear(ctx context.Context, year int) ([]int64, error)
+	GetCampaignSupportMessages(ctx context.Context, campaignID int64, limit, offset int) ([]models.SupportMessage, error)
+	UpdateMessageStatus(ctx context.Context, id int64, status string) error
+	GetCampaignPublicDonations(ctx context.Context, campaignID int64, limit, offset int) ([]models.PublicDonation, int64, error)
+	GetCampaignLeaderboard(ctx context.Context, campaignID int64, since *time.Time, limit int) ([]models.LeaderboardEntry, error)
+	GetCampaignStats(ctx context.Context, campaignID int64) (*models.CampaignStats, error)
+	GetCampaignDailyTotals(ctx context.Context, campaignID int64) ([]models.DailyTotal, error)
+	UpdatePaymentStatus(ctx context.Context, id int64, fromStatus, toStatus string) error
+	SetPaymentReference(ctx context.Context, id int64, paymentMethod, transactionID string) error
 }
 
 type DonationRepository struct {
@@ -29,39 +41,128 @@ func NewDonationRepository(db *pgxpool.Pool, schema string) *DonationRepository
 	}
 }
 
+const donationColumns = `
+	id, user_id, campaign_id, amount, currency, original_amount, exchange_rate::text, is_anonymous,
+	payment_status, COALESCE(payment_method, ''), COALESCE(transaction_id, ''),
+	COALESCE(message, ''), COALESCE(dedication_type, ''), COALESCE(dedication_name, ''), COALESCE(message_status, ''),
+	recurring_donation_id, created_at, modified_at
+`
+
+func scanDonation(row pgx.Row, d *models.Donation) error {
+	return row.Scan(
+		&d.ID,
+		&d.UserID,
+		&d.CampaignID,
+		&d.Amount,
+		&d.Currency,
+		&d.OriginalAmount,
+		&d.ExchangeRate,
+		&d.IsAnonymous,
+		&d.PaymentStatus,
+		&d.PaymentMethod,
+		&d.TransactionID,
+		&d.Message,
+		&d.DedicationType,
+		&d.DedicationName,
+		&d.MessageStatus,
+		&d.RecurringDonationID,
+		&d.CreatedAt,
+		&d.ModifiedAt,
+	)
+}
+
+func scanDonations(rows pgx.Rows) ([]models.Donation, error) {
+	defer rows.Close()
+
+	var donations []models.Donation
+	for rows.Next() {
+		var d models.Donation
+		if err := scanDonation(rows, &d); err != nil {
+			return nil, err
+		}
+		donations = append(donations, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return donations, nil
+}
+
 func (r *DonationRepository) CreateDonation(ctx context.Context, donation *models.Donation) error {
 	query := `
-		INSERT INTO donations (user_id, campaign_id, amount, is_anonymous, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
+		INSERT INTO donations (
+			user_id, campaign_id, amount, currency, original_amount, exchange_rate,
+			is_anonymous, payment_status, payment_method, transaction_id, recurring_donation_id,
+			message, dedication_type, dedication_name, message_status, created_at, modified_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6::numeric, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11,
+			NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, ''), NULLIF($15, ''), $16, $16
+		) RETURNING id, created_at, modified_at
 	`
 
-	return r.db.QueryRow(ctx, query,
+	if donation.PaymentStatus == "" {
+		donation.PaymentStatus = models.PaymentStatusPending
+	}
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
 		donation.UserID,
 		donation.CampaignID,
 		donation.Amount,
+		donation.Currency,
+		donation.OriginalAmount,
+		donation.ExchangeRate,
 		donation.IsAnonymous,
+		donation.PaymentStatus,
+		donation.PaymentMethod,
+		donation.TransactionID,
+		donation.RecurringDonationID,
+		donation.Message,
+		donation.DedicationType,
+		donation.DedicationName,
+		donation.MessageStatus,
 		time.Now(),
-	).Scan(&donation.ID)
+	).Scan(&donation.ID, &donation.CreatedAt, &donation.ModifiedAt)
 }
 
 func (r *DonationRepository) GetDonationByID(ctx context.Context, id int64) (*models.Donation, error) {
-	query := `
-		SELECT id, user_id, campaign_id, amount, is_anonymous, created_at
-		FROM donations
-		WHERE id = $1
-	`
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE id = $1`
 
 	var donation models.Donation
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&donation.ID,
-		&donation.UserID,
-		&donation.CampaignID,
-		&donation.Amount,
-		&donation.IsAnonymous,
-		&donation.CreatedAt,
-	)
+	err := scanDonation(conn(ctx, r.db).QueryRow(ctx, query, id), &donation)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
 
+	return &donation, nil
+}
+
+// LockDonation loads a donation and locks its row until the surrounding
+// transaction ends. Must run inside a transaction.
+func (r *DonationRepository) LockDonation(ctx context.Context, id int64) (*models.Donation, error) {
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE id = $1 FOR UPDATE`
+
+	var donation models.Donation
+	err := scanDonation(conn(ctx, r.db).QueryRow(ctx, query, id), &donation)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &donation, nil
+}
+
+func (r *DonationRepository) GetDonationByTransactionID(ctx context.Context, transactionID string) (*models.Donation, error) {
+	query := `SELECT ` + donationColumns + ` FROM donations WHERE transaction_id = $1`
+
+	var donation models.Donation
+	err := scanDonation(conn(ctx, r.db).QueryRow(ctx, query, transactionID), &donation)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -74,106 +175,321 @@ func (r *DonationRepository) GetDonationByID(ctx context.Context, id int64) (*mo
 
 func (r *DonationRepository) GetUserDonations(ctx context.Context, userID int64) ([]models.Donation, error) {
 	query := `
-		SELECT id, user_id, campaign_id, amount, is_anonymous, created_at
+		SELECT ` + donationColumns + `
 		FROM donations
 		WHERE user_id = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanDonations(rows)
+}
+
+func (r *DonationRepository) GetCampaignDonations(ctx context.Context, campaignID int64) ([]models.Donation, error) {
+	query := `
+		SELECT ` + donationColumns + `
+		FROM donations
+		WHERE campaign_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanDonations(rows)
+}
+
+func (r *DonationRepository) GetAllDonations(ctx context.Context) ([]models.Donation, error) {
+	query := `
+		SELECT ` + donationColumns + `
+		FROM donations
+		ORDER BY created_at DESC
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanDonations(rows)
+}
+
+// GetDonorIDsByYear returns the users with at least one paid donation in year
+func (r *DonationRepository) GetDonorIDsByYear(ctx context.Context, year int) ([]int64, error) {
+	query := `
+		SELECT DISTINCT user_id
+		FROM donations
+		WHERE payment_status = $1 AND EXTRACT(YEAR FROM created_at) = $2
+		ORDER BY user_id
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, models.PaymentStatusPaid, year)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// UpdatePaymentStatus moves a donation to toStatus only if it is still in
+// fromStatus, so two concurrent transitions cannot both succeed.
+func (r *DonationRepository) UpdatePaymentStatus(ctx context.Context, id int64, fromStatus, toStatus string) error {
+	query := `
+		UPDATE donations
+		SET payment_status = $1,
+			modified_at = $2
+		WHERE id = $3 AND payment_status = $4
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, toStatus, time.Now(), id, fromStatus)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("donation %d is no longer %s", id, fromStatus)
+	}
+
+	return nil
+}
+
+// SetPaymentReference stores the payment method and the gateway's transaction ID
+func (r *DonationRepository) SetPaymentReference(ctx context.Context, id int64, paymentMethod, transactionID string) error {
+	query := `
+		UPDATE donations
+		SET payment_method = NULLIF($1, ''),
+			transaction_id = NULLIF($2, ''),
+			modified_at = $3
+		WHERE id = $4
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, paymentMethod, transactionID, time.Now(), id)
+	return err
+}
+
+// GetCampaignSupportMessages returns the approved messages left on paid
+// donations to a campaign, newest first. Anonymous donors get no name.
+func (r *DonationRepository) GetCampaignSupportMessages(ctx context.Context, campaignID int64, limit, offset int) ([]models.SupportMessage, error) {
+	query := `
+		SELECT
+			d.id,
+			CASE WHEN d.is_anonymous THEN '' ELSE COALESCE(NULLIF(u.fullname, ''), u.username) END,
+			d.is_anonymous,
+			COALESCE(d.message, ''),
+			COALESCE(d.dedication_type, ''),
+			COALESCE(d.dedication_name, ''),
+			d.created_at
+		FROM donations d
+		JOIN users u ON u.user_id = d.user_id
+		WHERE d.campaign_id = $1 AND d.payment_status = $2 AND d.message_status = $3
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, campaignID, models.PaymentStatusPaid, models.MessageStatusApproved, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var donations []models.Donation
+	var messages []models.SupportMessage
 	for rows.Next() {
-		var d models.Donation
-		if err := rows.Scan(
-			&d.ID,
-			&d.UserID,
-			&d.CampaignID,
-			&d.Amount,
-			&d.IsAnonymous,
-			&d.CreatedAt,
-		); err != nil {
+		var m models.SupportMessage
+		if err := rows.Scan(&m.DonationID, &m.DonorName, &m.IsAnonymous, &m.Message, &m.DedicationType, &m.DedicationName, &m.CreatedAt); err != nil {
 			return nil, err
 		}
-		donations = append(donations, d)
+		messages = append(messages, m)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return donations, nil
+	return messages, nil
 }
 
-func (r *DonationRepository) GetCampaignDonations(ctx context.Context, campaignID int64) ([]models.Donation, error) {
+// UpdateMessageStatus overrides the automatic moderation of a donor message
+func (r *DonationRepository) UpdateMessageStatus(ctx context.Context, id int64, status string) error {
 	query := `
-		SELECT id, user_id, campaign_id, amount, is_anonymous, created_at
-		FROM donations
-		WHERE campaign_id = $1
-		ORDER BY created_at DESC
+		UPDATE donations
+		SET message_status = $1,
+			modified_at = $2
+		WHERE id = $3 AND message_status IS NOT NULL
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, status, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("donation %d has no message", id)
+	}
+
+	return nil
+}
+
+// paidNetDonations selects the paid donations of campaign $1 with refunds
+// taken off, leaving out donations that were refunded in full
+const paidNetDonations = `
+	SELECT d.id, d.user_id, d.is_anonymous, d.created_at,
+		d.amount - COALESCE((SELECT SUM(dr.amount) FROM donation_refunds dr WHERE dr.donation_id = d.id AND dr.status <> 'failed'), 0) AS net_amount
+	FROM donations d
+	WHERE d.campaign_id = $1 AND d.payment_status = 'paid'
+`
+
+// publicDonorName hides the name of anonymous donors
+const publicDonorName = `CASE WHEN n.is_anonymous THEN '' ELSE COALESCE(NULLIF(u.fullname, ''), u.username) END`
+
+// GetCampaignPublicDonations returns one page of a campaign's paid donations,
+// newest first, together with the total number of donations
+func (r *DonationRepository) GetCampaignPublicDonations(ctx context.Context, campaignID int64, limit, offset int) ([]models.PublicDonation, int64, error) {
+	query := `
+		WITH n AS (` + paidNetDonations + `)
+		SELECT n.id, ` + publicDonorName + `, n.is_anonymous, n.net_amount, n.created_at, COUNT(*) OVER ()
+		FROM n
+		JOIN users u ON u.user_id = n.user_id
+		WHERE n.net_amount > 0
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Query(ctx, query, campaignID)
+	rows, err := conn(ctx, r.db).Query(ctx, query, campaignID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var donations []models.PublicDonation
+	var total int64
+	for rows.Next() {
+		var d models.PublicDonation
+		if err := rows.Scan(&d.DonationID, &d.DonorName, &d.IsAnonymous, &d.Amount, &d.CreatedAt, &total); err != nil {
+			return nil, 0, err
+		}
+		donations = append(donations, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return donations, total, nil
+}
+
+// GetCampaignLeaderboard ranks donors by the net amount they gave to a
+// campaign since the given time, or over all time when since is nil
+func (r *DonationRepository) GetCampaignLeaderboard(ctx context.Context, campaignID int64, since *time.Time, limit int) ([]models.LeaderboardEntry, error) {
+	query := `
+		WITH n AS (` + paidNetDonations + ` AND ($2::timestamptz IS NULL OR d.created_at >= $2))
+		SELECT ` + publicDonorName + `, n.is_anonymous, SUM(n.net_amount), COUNT(*)
+		FROM n
+		JOIN users u ON u.user_id = n.user_id
+		WHERE n.net_amount > 0
+		GROUP BY n.user_id, n.is_anonymous, u.fullname, u.username
+		ORDER BY SUM(n.net_amount) DESC, MIN(n.created_at)
+		LIMIT $3
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, campaignID, since, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var donations []models.Donation
+	var entries []models.LeaderboardEntry
 	for rows.Next() {
-		var d models.Donation
-		if err := rows.Scan(
-			&d.ID,
-			&d.UserID,
-			&d.CampaignID,
-			&d.Amount,
-			&d.IsAnonymous,
-			&d.CreatedAt,
-		); err != nil {
+		var e models.LeaderboardEntry
+		if err := rows.Scan(&e.DonorName, &e.IsAnonymous, &e.TotalAmount, &e.DonationCount); err != nil {
 			return nil, err
 		}
-		donations = append(donations, d)
+		entries = append(entries, e)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return donations, nil
+	return entries, nil
 }
 
-func (r *DonationRepository) GetAllDonations(ctx context.Context) ([]models.Donation, error) {
+// GetCampaignStats aggregates a campaign's paid donations in a single query.
+// Averages are rounded to cents so they scan into Money.
+func (r *DonationRepository) GetCampaignStats(ctx context.Context, campaignID int64) (*models.CampaignStats, error) {
 	query := `
-		SELECT id, user_id, campaign_id, amount, is_anonymous, created_at
-		FROM donations
-		ORDER BY created_at DESC
+		WITH n AS (` + paidNetDonations + `)
+		SELECT
+			COALESCE(SUM(n.net_amount), 0),
+			COUNT(DISTINCT n.user_id),
+			COUNT(*),
+			COALESCE(ROUND(AVG(n.net_amount), 2), 0),
+			COALESCE(ROUND((PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY n.net_amount))::numeric, 2), 0),
+			COUNT(*) FILTER (WHERE n.is_anonymous),
+			COALESCE(SUM(n.net_amount) FILTER (WHERE n.is_anonymous), 0)
+		FROM n
+		WHERE n.net_amount > 0
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	var stats models.CampaignStats
+	err := conn(ctx, r.db).QueryRow(ctx, query, campaignID).Scan(
+		&stats.TotalRaised,
+		&stats.DonorCount,
+		&stats.DonationCount,
+		&stats.AverageGift,
+		&stats.MedianGift,
+		&stats.AnonymousDonationCount,
+		&stats.AnonymousAmount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// GetCampaignDailyTotals returns the amount raised per day from the first
+// to the last paid donation, including days without donations
+func (r *DonationRepository) GetCampaignDailyTotals(ctx context.Context, campaignID int64) ([]models.DailyTotal, error) {
+	query := `
+		WITH n AS (` + paidNetDonations + `),
+		daily AS (
+			SELECT date_trunc('day', n.created_at) AS day, SUM(n.net_amount) AS amount, COUNT(*) AS donation_count
+			FROM n
+			WHERE n.net_amount > 0
+			GROUP BY 1
+		)
+		SELECT days.day, COALESCE(daily.amount, 0), COALESCE(daily.donation_count, 0)
+		FROM generate_series(
+			(SELECT MIN(day) FROM daily),
+			(SELECT MAX(day) FROM daily),
+			INTERVAL '1 day'
+		) AS days(day)
+		LEFT JOIN daily ON daily.day = days.day
+		ORDER BY days.day
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, campaignID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var donations []models.Donation
+	totals := []models.DailyTotal{}
 	for rows.Next() {
-		var d models.Donation
-		if err := rows.Scan(
-			&d.ID,
-			&d.UserID,
-			&d.CampaignID,
-			&d.Amount,
-			&d.IsAnonymous,
-			&d.CreatedAt,
-		); err != nil {
+		var t models.DailyTotal
+		if err := rows.Scan(&t.Date, &t.Amount, &t.DonationCount); err != nil {
 			return nil, err
 		}
-		donations = append(donations, d)
+		totals = append(totals, t)
 	}
 
-	return donations, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
 }
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EmailOutboxRepositoryInterface interface {
+	CreateEmail(ctx context.Context, email *models.OutboxEmail) error
+	LockDueEmails(ctx context.Context, now time.Time, limit int) ([]models.OutboxEmail, error)
+	MarkSent(ctx context.Context, id int64, sentAt time.Time) error
+	MarkAttemptFailed(ctx context.Context, id int64, status, lastError string, nextAttemptAt time.Time) error
+	ListEmails(ctx context.Context, status string, limit, offset int) ([]models.OutboxEmail, error)
+	GetEmail(ctx context.Context, id int64) (*models.OutboxEmail, error)
+	RequeueEmail(ctx context.Context, id int64, now time.Time) error
+}
+
+type EmailOutboxRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewEmailOutboxRepository(db *pgxpool.Pool, schema string) *EmailOutboxRepository {
+	return &EmailOutboxRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+const outboxEmailColumns = `
+	id, recipient, subject, text_body, COALESCE(html_body, ''), template, locale,
+	status, attempts, next_attempt_at, COALESCE(last_error, ''), sent_at, created_at, modified_at
+`
+
+func scanOutboxEmail(row pgx.Row, email *models.OutboxEmail) error {
+	return row.Scan(
+		&email.ID,
+		&email.Recipient,
+		&email.Subject,
+		&email.TextBody,
+		&email.HTMLBody,
+		&email.Template,
+		&email.Locale,
+		&email.Status,
+		&email.Attempts,
+		&email.NextAttemptAt,
+		&email.LastError,
+		&email.SentAt,
+		&email.CreatedAt,
+		&email.ModifiedAt,
+	)
+}
+
+func scanOutboxEmails(rows pgx.Rows) ([]models.OutboxEmail, error) {
+	defer rows.Close()
+
+	var emails []models.OutboxEmail
+	for rows.Next() {
+		var email models.OutboxEmail
+		if err := scanOutboxEmail(rows, &email); err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+// CreateEmail queues an email. It joins the transaction in ctx, so the email
+// is only sent when the change that triggered it is committed.
+func (r *EmailOutboxRepository) CreateEmail(ctx context.Context, email *models.OutboxEmail) error {
+	query := `
+		INSERT INTO email_outbox (recipient, subject, text_body, html_body, template, locale, status, next_attempt_at, created_at, modified_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $8, $8)
+		RETURNING ` + outboxEmailColumns
+
+	return scanOutboxEmail(conn(ctx, r.db).QueryRow(ctx, query,
+		email.Recipient,
+		email.Subject,
+		email.TextBody,
+		email.HTMLBody,
+		email.Template,
+		email.Locale,
+		models.EmailStatusPending,
+		time.Now(),
+	), email)
+}
+
+// LockDueEmails locks pending emails whose next attempt is due. Rows locked
+// by another worker are skipped.
+func (r *EmailOutboxRepository) LockDueEmails(ctx context.Context, now time.Time, limit int) ([]models.OutboxEmail, error) {
+	query := `
+		SELECT ` + outboxEmailColumns + `
+		FROM email_outbox
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY next_attempt_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, models.EmailStatusPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOutboxEmails(rows)
+}
+
+// MarkSent records the delivery and clears the bodies, which may hold links
+// that grant access to an account
+func (r *EmailOutboxRepository) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	query := `
+		UPDATE email_outbox
+		SET status = $2, attempts = attempts + 1, sent_at = $3, last_error = NULL,
+			text_body = '', html_body = NULL, modified_at = $3
+		WHERE id = $1
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, id, models.EmailStatusSent, sentAt)
+	return err
+}
+
+// MarkAttemptFailed records a failed delivery. The email stays pending until
+// nextAttemptAt, or moves to failed when it ran out of attempts.
+func (r *EmailOutboxRepository) MarkAttemptFailed(ctx context.Context, id int64, status, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE email_outbox
+		SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4, modified_at = $5
+		WHERE id = $1
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, id, status, lastError, nextAttemptAt, time.Now())
+	return err
+}
+
+// ListEmails returns emails newest first, optionally filtered by status
+func (r *EmailOutboxRepository) ListEmails(ctx context.Context, status string, limit, offset int) ([]models.OutboxEmail, error) {
+	query := `
+		SELECT ` + outboxEmailColumns + `
+		FROM email_outbox
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOutboxEmails(rows)
+}
+
+func (r *EmailOutboxRepository) GetEmail(ctx context.Context, id int64) (*models.OutboxEmail, error) {
+	query := `SELECT ` + outboxEmailColumns + ` FROM email_outbox WHERE id = $1`
+
+	var email models.OutboxEmail
+	if err := scanOutboxEmail(conn(ctx, r.db).QueryRow(ctx, query, id), &email); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &email, nil
+}
+
+// RequeueEmail gives a failed email a fresh set of attempts
+func (r *EmailOutboxRepository) RequeueEmail(ctx context.Context, id int64, now time.Time) error {
+	query := `
+		UPDATE email_outbox
+		SET status = $2, attempts = 0, next_attempt_at = $4, modified_at = $4
+		WHERE id = $1 AND status = $3
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id, models.EmailStatusPending, models.EmailStatusFailed, now)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("email not found")
+	}
+
+	return nil
+}
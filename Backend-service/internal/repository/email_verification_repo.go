@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EmailVerificationRepositoryInterface interface {
+	CreateToken(ctx context.Context, token *models.EmailVerificationToken) error
+	GetTokenForUpdate(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error)
+	GetLatestTokenTime(ctx context.Context, userID int64) (*time.Time, error)
+	InvalidateUserTokens(ctx context.Context, userID int64) error
+}
+
+type EmailVerificationRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewEmailVerificationRepository(db *pgxpool.Pool, schema string) *EmailVerificationRepository {
+	return &EmailVerificationRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+func (r *EmailVerificationRepository) CreateToken(ctx context.Context, token *models.EmailVerificationToken) error {
+	query := `
+		INSERT INTO email_verification_tokens (user_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		token.UserID,
+		token.TokenHash,
+		time.Now(),
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetTokenForUpdate locks the token so two requests cannot both redeem it
+func (r *EmailVerificationRepository) GetTokenForUpdate(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, created_at, expires_at, used_at
+		FROM email_verification_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`
+
+	var token models.EmailVerificationToken
+	err := conn(ctx, r.db).QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.UsedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// GetLatestTokenTime returns when the user was last sent a token, or nil if never
+func (r *EmailVerificationRepository) GetLatestTokenTime(ctx context.Context, userID int64) (*time.Time, error) {
+	query := `SELECT MAX(created_at) FROM email_verification_tokens WHERE user_id = $1`
+
+	var latest *time.Time
+	if err := conn(ctx, r.db).QueryRow(ctx, query, userID).Scan(&latest); err != nil {
+		return nil, err
+	}
+
+	return latest, nil
+}
+
+// InvalidateUserTokens marks every outstanding token of the user as used
+func (r *EmailVerificationRepository) InvalidateUserTokens(ctx context.Context, userID int64) error {
+	query := `UPDATE email_verification_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), userID)
+	return err
+}
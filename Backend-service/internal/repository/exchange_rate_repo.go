@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExchangeRateRepositoryInterface interface {
+	UpsertRate(ctx context.Context, rate *models.ExchangeRate) error
+	GetRate(ctx context.Context, baseCurrency, quoteCurrency string) (*models.ExchangeRate, error)
+	ListRates(ctx context.Context) ([]models.ExchangeRate, error)
+	DeleteRate(ctx context.Context, baseCurrency, quoteCurrency string) error
+}
+
+type ExchangeRateRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewExchangeRateRepository(db *pgxpool.Pool, schema string) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+const exchangeRateColumns = `
+	id, base_currency, quote_currency, rate::text,
+	COALESCE(created_by, ''), created_at, COALESCE(modified_by, ''), modified_at
+`
+
+func scanExchangeRate(row pgx.Row, rate *models.ExchangeRate) error {
+	return row.Scan(
+		&rate.ID,
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.CreatedBy,
+		&rate.CreatedAt,
+		&rate.ModifiedBy,
+		&rate.ModifiedAt,
+	)
+}
+
+func (r *ExchangeRateRepository) UpsertRate(ctx context.Context, rate *models.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, created_by, created_at, modified_by, modified_at)
+		VALUES ($1, $2, $3::numeric, $4, $5, $4, $5)
+		ON CONFLICT (base_currency, quote_currency) DO UPDATE
+		SET rate = EXCLUDED.rate,
+			modified_by = EXCLUDED.modified_by,
+			modified_at = EXCLUDED.modified_at
+		RETURNING ` + exchangeRateColumns
+
+	return scanExchangeRate(conn(ctx, r.db).QueryRow(ctx, query,
+		rate.BaseCurrency,
+		rate.QuoteCurrency,
+		rate.Rate,
+		rate.ModifiedBy,
+		time.Now(),
+	), rate)
+}
+
+func (r *ExchangeRateRepository) GetRate(ctx context.Context, baseCurrency, quoteCurrency string) (*models.ExchangeRate, error) {
+	query := `SELECT ` + exchangeRateColumns + ` FROM exchange_rates WHERE base_currency = $1 AND quote_currency = $2`
+
+	var rate models.ExchangeRate
+	if err := scanExchangeRate(conn(ctx, r.db).QueryRow(ctx, query, baseCurrency, quoteCurrency), &rate); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+func (r *ExchangeRateRepository) ListRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	query := `SELECT ` + exchangeRateColumns + ` FROM exchange_rates ORDER BY base_currency, quote_currency`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []models.ExchangeRate
+	for rows.Next() {
+		var rate models.ExchangeRate
+		if err := scanExchangeRate(rows, &rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *ExchangeRateRepository) DeleteRate(ctx context.Context, baseCurrency, quoteCurrency string) error {
+	query := `DELETE FROM exchange_rates WHERE base_currency = $1 AND quote_currency = $2`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, baseCurrency, quoteCurrency)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("exchange rate not found")
+	}
+
+	return nil
+}
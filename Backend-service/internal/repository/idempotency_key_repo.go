@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyKeyRepositoryInterface interface {
+	Reserve(ctx context.Context, key *models.IdempotencyKey) (bool, error)
+	GetKey(ctx context.Context, userID int64, key string) (*models.IdempotencyKey, error)
+	SaveResponse(ctx context.Context, userID int64, key string, statusCode int, body []byte) error
+	Release(ctx context.Context, userID int64, key string) error
+}
+
+type IdempotencyKeyRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewIdempotencyKeyRepository(db *pgxpool.Pool, schema string) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+// Reserve claims the key for a new request. It returns false when an
+// unexpired record for the same user and key already exists.
+func (r *IdempotencyKeyRepository) Reserve(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < EXCLUDED.created_at
+		RETURNING created_at
+	`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		key.UserID,
+		key.Key,
+		key.RequestHash,
+		time.Now(),
+		key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *IdempotencyKeyRepository) GetKey(ctx context.Context, userID int64, key string) (*models.IdempotencyKey, error) {
+	query := `
+		SELECT user_id, idempotency_key, request_hash, status_code, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+	`
+
+	var k models.IdempotencyKey
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, key).Scan(
+		&k.UserID,
+		&k.Key,
+		&k.RequestHash,
+		&k.StatusCode,
+		&k.ResponseBody,
+		&k.CreatedAt,
+		&k.ExpiresAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+func (r *IdempotencyKeyRepository) SaveResponse(ctx context.Context, userID int64, key string, statusCode int, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_body = $2
+		WHERE user_id = $3 AND idempotency_key = $4
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, statusCode, body, userID, key)
+	return err
+}
+
+// Release drops an unfinished reservation so the client can retry with the same key
+func (r *IdempotencyKeyRepository) Release(ctx context.Context, userID int64, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2 AND status_code IS NULL`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, userID, key)
+	return err
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type InvitationRepositoryInterface interface {
+	CreateInvitation(ctx context.Context, invitation *models.Invitation) error
+	GetInvitation(ctx context.Context, id int64) (*models.Invitation, error)
+	GetInvitationForUpdate(ctx context.Context, id int64) (*models.Invitation, error)
+	ListInvitations(ctx context.Context, limit, offset int) ([]models.Invitation, error)
+	RevokeInvitation(ctx context.Context, id int64, revokedBy string) (bool, error)
+	RevokePendingForEmail(ctx context.Context, email, revokedBy string) error
+	MarkAccepted(ctx context.Context, id, userID int64) error
+}
+
+type InvitationRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewInvitationRepository(db *pgxpool.Pool, schema string) *InvitationRepository {
+	return &InvitationRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+const invitationColumns = `
+	i.id, i.email, i.role_id, r.role_name, i.expires_at, i.accepted_at, i.accepted_user_id,
+	i.revoked_at, COALESCE(i.revoked_by, ''), COALESCE(i.created_by, ''), i.created_at
+`
+
+func scanInvitation(row pgx.Row, invitation *models.Invitation) error {
+	return row.Scan(
+		&invitation.ID,
+		&invitation.Email,
+		&invitation.RoleID,
+		&invitation.RoleName,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.AcceptedUserID,
+		&invitation.RevokedAt,
+		&invitation.RevokedBy,
+		&invitation.CreatedBy,
+		&invitation.CreatedAt,
+	)
+}
+
+func (r *InvitationRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	query := `
+		INSERT INTO invitations (email, role_id, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		invitation.Email,
+		invitation.RoleID,
+		invitation.ExpiresAt,
+		invitation.CreatedBy,
+		time.Now(),
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+}
+
+func (r *InvitationRepository) GetInvitation(ctx context.Context, id int64) (*models.Invitation, error) {
+	return r.getInvitation(ctx, id, "")
+}
+
+// GetInvitationForUpdate locks the invitation so it can only be accepted once
+func (r *InvitationRepository) GetInvitationForUpdate(ctx context.Context, id int64) (*models.Invitation, error) {
+	return r.getInvitation(ctx, id, " FOR UPDATE OF i")
+}
+
+func (r *InvitationRepository) getInvitation(ctx context.Context, id int64, lock string) (*models.Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM invitations i
+		JOIN roles r ON r.role_id = i.role_id
+		WHERE i.id = $1` + lock
+
+	var invitation models.Invitation
+	if err := scanInvitation(conn(ctx, r.db).QueryRow(ctx, query, id), &invitation); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// ListInvitations returns invitations newest first
+func (r *InvitationRepository) ListInvitations(ctx context.Context, limit, offset int) ([]models.Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM invitations i
+		JOIN roles r ON r.role_id = i.role_id
+		ORDER BY i.created_at DESC, i.id DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []models.Invitation
+	for rows.Next() {
+		var invitation models.Invitation
+		if err := scanInvitation(rows, &invitation); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation revokes an invitation that was neither accepted nor revoked yet
+func (r *InvitationRepository) RevokeInvitation(ctx context.Context, id int64, revokedBy string) (bool, error) {
+	query := `
+		UPDATE invitations
+		SET revoked_at = $1, revoked_by = $2
+		WHERE id = $3 AND accepted_at IS NULL AND revoked_at IS NULL`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), revokedBy, id)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// RevokePendingForEmail revokes the open invitations of an email address, so
+// only the newest link works
+func (r *InvitationRepository) RevokePendingForEmail(ctx context.Context, email, revokedBy string) error {
+	query := `
+		UPDATE invitations
+		SET revoked_at = $1, revoked_by = $2
+		WHERE LOWER(email) = LOWER($3) AND accepted_at IS NULL AND revoked_at IS NULL`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), revokedBy, email)
+	return err
+}
+
+func (r *InvitationRepository) MarkAccepted(ctx context.Context, id, userID int64) error {
+	query := `UPDATE invitations SET accepted_at = $1, accepted_user_id = $2 WHERE id = $3`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), userID, id)
+	return err
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PasswordResetRepositoryInterface interface {
+	CreateToken(ctx context.Context, token *models.PasswordResetToken) error
+	GetTokenForUpdate(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	InvalidateUserTokens(ctx context.Context, userID int64) error
+}
+
+type PasswordResetRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewPasswordResetRepository(db *pgxpool.Pool, schema string) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+func (r *PasswordResetRepository) CreateToken(ctx context.Context, token *models.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		token.UserID,
+		token.TokenHash,
+		time.Now(),
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetTokenForUpdate locks the token so two requests cannot both redeem it
+func (r *PasswordResetRepository) GetTokenForUpdate(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, created_at, expires_at, used_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`
+
+	var token models.PasswordResetToken
+	err := conn(ctx, r.db).QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.UsedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// InvalidateUserTokens marks every outstanding token of the user as used
+func (r *PasswordResetRepository) InvalidateUserTokens(ctx context.Context, userID int64) error {
+	query := `UPDATE password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), userID)
+	return err
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PaymentWebhookEventRepositoryInterface interface {
+	SaveEvent(ctx context.Context, event *models.PaymentWebhookEvent) error
+	LockEvent(ctx context.Context, id int64) (*models.PaymentWebhookEvent, error)
+	MarkProcessed(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, processingError string) error
+	GetEventByID(ctx context.Context, id int64) (*models.PaymentWebhookEvent, error)
+	ListEvents(ctx context.Context, limit, offset int) ([]models.PaymentWebhookEvent, error)
+}
+
+type PaymentWebhookEventRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewPaymentWebhookEventRepository(db *pgxpool.Pool, schema string) *PaymentWebhookEventRepository {
+	return &PaymentWebhookEventRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+const paymentWebhookEventColumns = `
+	id, provider, event_id, COALESCE(transaction_id, ''), COALESCE(status, ''),
+	payload, COALESCE(signature, ''), processed, COALESCE(processing_error, ''),
+	received_at, processed_at
+`
+
+func scanPaymentWebhookEvent(row pgx.Row, e *models.PaymentWebhookEvent) error {
+	return row.Scan(
+		&e.ID,
+		&e.Provider,
+		&e.EventID,
+		&e.TransactionID,
+		&e.Status,
+		&e.Payload,
+		&e.Signature,
+		&e.Processed,
+		&e.ProcessingError,
+		&e.ReceivedAt,
+		&e.ProcessedAt,
+	)
+}
+
+// SaveEvent stores a received event. A retried delivery of the same
+// (provider, event_id) returns the row that is already stored.
+func (r *PaymentWebhookEventRepository) SaveEvent(ctx context.Context, event *models.PaymentWebhookEvent) error {
+	query := `
+		INSERT INTO payment_webhook_events (provider, event_id, transaction_id, status, payload, signature, received_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (provider, event_id) DO UPDATE SET provider = EXCLUDED.provider
+		RETURNING id, processed, received_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		event.Provider,
+		event.EventID,
+		event.TransactionID,
+		event.Status,
+		event.Payload,
+		event.Signature,
+		time.Now(),
+	).Scan(&event.ID, &event.Processed, &event.ReceivedAt)
+}
+
+// LockEvent loads an event with a row lock; it must run inside a transaction
+func (r *PaymentWebhookEventRepository) LockEvent(ctx context.Context, id int64) (*models.PaymentWebhookEvent, error) {
+	query := `SELECT ` + paymentWebhookEventColumns + ` FROM payment_webhook_events WHERE id = $1 FOR UPDATE`
+
+	var event models.PaymentWebhookEvent
+	if err := scanPaymentWebhookEvent(conn(ctx, r.db).QueryRow(ctx, query, id), &event); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("webhook event not found")
+		}
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (r *PaymentWebhookEventRepository) MarkProcessed(ctx context.Context, id int64) error {
+	query := `
+		UPDATE payment_webhook_events
+		SET processed = true, processing_error = NULL, processed_at = $1
+		WHERE id = $2
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), id)
+	return err
+}
+
+func (r *PaymentWebhookEventRepository) MarkFailed(ctx context.Context, id int64, processingError string) error {
+	query := `UPDATE payment_webhook_events SET processing_error = $1 WHERE id = $2`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, processingError, id)
+	return err
+}
+
+func (r *PaymentWebhookEventRepository) GetEventByID(ctx context.Context, id int64) (*models.PaymentWebhookEvent, error) {
+	query := `SELECT ` + paymentWebhookEventColumns + ` FROM payment_webhook_events WHERE id = $1`
+
+	var event models.PaymentWebhookEvent
+	if err := scanPaymentWebhookEvent(conn(ctx, r.db).QueryRow(ctx, query, id), &event); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (r *PaymentWebhookEventRepository) ListEvents(ctx context.Context, limit, offset int) ([]models.PaymentWebhookEvent, error) {
+	query := `
+		SELECT ` + paymentWebhookEventColumns + `
+		FROM payment_webhook_events
+		ORDER BY received_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.PaymentWebhookEvent
+	for rows.Next() {
+		var e models.PaymentWebhookEvent
+		if err := scanPaymentWebhookEvent(rows, &e); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RecurringDonationRepositoryInterface interface {
+	CreateRecurringDonation(ctx context.Context, recurring *models.RecurringDonation) error
+	GetRecurringDonationByID(ctx context.Context, id int64) (*models.RecurringDonation, error)
+	GetUserRecurringDonations(ctx context.Context, userID int64) ([]models.RecurringDonation, error)
+	UpdateStatus(ctx context.Context, id int64, status string, nextChargeDate time.Time, modifiedBy string) error
+	LockDueRecurringDonations(ctx context.Context, now time.Time, excludeIDs []int64, limit int) ([]models.RecurringDonation, error)
+	AdvanceNextChargeDate(ctx context.Context, id int64, chargedAt, nextChargeDate time.Time) error
+}
+
+type RecurringDonationRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewRecurringDonationRepository(db *pgxpool.Pool, schema string) *RecurringDonationRepository {
+	return &RecurringDonationRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+const recurringDonationColumns = `
+	id, user_id, campaign_id, amount, currency, is_anonymous, COALESCE(payment_method, ''),
+	interval, anchor_day, next_charge_date, last_charged_at, status,
+	COALESCE(created_by, ''), created_at, COALESCE(modified_by, ''), modified_at
+`
+
+func scanRecurringDonation(row pgx.Row, r *models.RecurringDonation) error {
+	return row.Scan(
+		&r.ID,
+		&r.UserID,
+		&r.CampaignID,
+		&r.Amount,
+		&r.Currency,
+		&r.IsAnonymous,
+		&r.PaymentMethod,
+		&r.Interval,
+		&r.AnchorDay,
+		&r.NextChargeDate,
+		&r.LastChargedAt,
+		&r.Status,
+		&r.CreatedBy,
+		&r.CreatedAt,
+		&r.ModifiedBy,
+		&r.ModifiedAt,
+	)
+}
+
+func scanRecurringDonations(rows pgx.Rows) ([]models.RecurringDonation, error) {
+	defer rows.Close()
+
+	var recurring []models.RecurringDonation
+	for rows.Next() {
+		var r models.RecurringDonation
+		if err := scanRecurringDonation(rows, &r); err != nil {
+			return nil, err
+		}
+		recurring = append(recurring, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recurring, nil
+}
+
+func (r *RecurringDonationRepository) CreateRecurringDonation(ctx context.Context, recurring *models.RecurringDonation) error {
+	query := `
+		INSERT INTO recurring_donations (
+			user_id, campaign_id, amount, currency, is_anonymous, payment_method,
+			interval, anchor_day, next_charge_date, status, created_by, created_at, modified_by, modified_at
+		) VALUES (
+			$1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, $11, $12, $11, $12
+		) RETURNING id, created_at, modified_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		recurring.UserID,
+		recurring.CampaignID,
+		recurring.Amount,
+		recurring.Currency,
+		recurring.IsAnonymous,
+		recurring.PaymentMethod,
+		recurring.Interval,
+		recurring.AnchorDay,
+		recurring.NextChargeDate,
+		recurring.Status,
+		recurring.CreatedBy,
+		time.Now(),
+	).Scan(&recurring.ID, &recurring.CreatedAt, &recurring.ModifiedAt)
+}
+
+func (r *RecurringDonationRepository) GetRecurringDonationByID(ctx context.Context, id int64) (*models.RecurringDonation, error) {
+	query := `SELECT ` + recurringDonationColumns + ` FROM recurring_donations WHERE id = $1`
+
+	var recurring models.RecurringDonation
+	err := scanRecurringDonation(conn(ctx, r.db).QueryRow(ctx, query, id), &recurring)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &recurring, nil
+}
+
+func (r *RecurringDonationRepository) GetUserRecurringDonations(ctx context.Context, userID int64) ([]models.RecurringDonation, error) {
+	query := `SELECT ` + recurringDonationColumns + ` FROM recurring_donations WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRecurringDonations(rows)
+}
+
+func (r *RecurringDonationRepository) UpdateStatus(ctx context.Context, id int64, status string, nextChargeDate time.Time, modifiedBy string) error {
+	query := `
+		UPDATE recurring_donations
+		SET status = $2, next_charge_date = $3, modified_by = $4, modified_at = $5
+		WHERE id = $1
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id, status, nextChargeDate, modifiedBy, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("recurring donation not found")
+	}
+
+	return nil
+}
+
+// LockDueRecurringDonations locks active schedules that are due. Rows already
+// locked by another server instance are skipped, so each schedule is charged
+// by exactly one process. Must run inside a transaction.
+func (r *RecurringDonationRepository) LockDueRecurringDonations(ctx context.Context, now time.Time, excludeIDs []int64, limit int) ([]models.RecurringDonation, error) {
+	query := `
+		SELECT ` + recurringDonationColumns + `
+		FROM recurring_donations
+		WHERE status = $1 AND next_charge_date <= $2 AND NOT (id = ANY($3))
+		ORDER BY next_charge_date
+		LIMIT $4
+		FOR UPDATE SKIP LOCKED
+	`
+
+	// A nil slice would be sent as NULL and exclude every row
+	if excludeIDs == nil {
+		excludeIDs = []int64{}
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, models.RecurringStatusActive, now, excludeIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRecurringDonations(rows)
+}
+
+func (r *RecurringDonationRepository) AdvanceNextChargeDate(ctx context.Context, id int64, chargedAt, nextChargeDate time.Time) error {
+	query := `
+		UPDATE recurring_donations
+		SET last_charged_at = $2, next_charge_date = $3, modified_by = 'system', modified_at = $2
+		WHERE id = $1
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id, chargedAt, nextChargeDate)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("recurring donation not found")
+	}
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 
 type RoleRepositoryInterface interface {
 	GetRoleByID(roleID int64) (*models.Role, error)
+	GetRoleByName(roleName string) (*models.Role, error)
 }
 
 type RoleRepository struct {
@@ -31,7 +32,7 @@ func (r *RoleRepository) GetRoleByID(roleID int64) (*models.Role, error) {
 		SELECT 
 			role_id, 
 			role_name, 
-			role_description,
+			COALESCE(role_description, ''),
 			created_at,
 			modified_at
 		FROM roles 
@@ -59,22 +60,24 @@ func (r *RoleRepository) GetRoleByID(roleID int64) (*models.Role, error) {
 }
 
 func (r *RoleRepository) GetRoleByName(roleName string) (*models.Role, error) {
-
 	query := `
 		SELECT 
 			role_id, 
 			role_name, 
-			role_description
+			COALESCE(role_description, ''),
+			created_at,
+			modified_at
 		FROM roles
-		WHERE name = $1 AND
-		 is_active = true
-		`
+		WHERE role_name = $1 AND is_active = true
+	`
 
 	var role models.Role
 	err := r.db.QueryRow(context.Background(), query, roleName).Scan(
 		&role.RoleID,
-		&roleName,
+		&role.RoleName,
 		&role.RoleDescription,
+		&role.CreatedAt,
+		&role.ModifiedAt,
 	)
 
 	if err != nil {
@@ -89,7 +92,7 @@ func (r *RoleRepository) GetRoleByName(roleName string) (*models.Role, error) {
 
 func (r *RoleRepository) CreateRole(role *models.Role) error {
 	query := `
-		INSERT INTO roles (role_name, role_description, created_at, updated_at)
+		INSERT INTO roles (role_name, role_description, created_at, modified_at)
 		VALUES ($1, $2, $3, $4)
 		RETURNING role_id
 	`
@@ -0,0 +1,565 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/testdb"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The schema contract tests run the repository queries against a database
+// migrated from migrations/, so a column renamed on one side and not the
+// other fails here instead of at runtime.
+
+func TestSchemaHasNoSeededUsers(t *testing.T) {
+	db := testdb.Open(t)
+
+	var count int
+	if err := db.QueryRow(context.Background(), `SELECT COUNT(*) FROM users`).Scan(&count); err != nil {
+		t.Fatalf("failed to count users: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("migrations created %d users, want none", count)
+	}
+}
+
+func TestSchemaRolesAreSeeded(t *testing.T) {
+	db := testdb.Open(t)
+	roles := NewRoleRepository(db, "public")
+
+	for _, name := range []string{models.RoleSuperadmin, models.RoleDonor, models.RoleRecipient} {
+		role, err := roles.GetRoleByName(name)
+		if err != nil {
+			t.Fatalf("failed to get role %s: %v", name, err)
+		}
+		byID, err := roles.GetRoleByID(role.RoleID)
+		if err != nil || byID.RoleName != name {
+			t.Fatalf("role %d = %+v (err %v), want %s", role.RoleID, byID, err, name)
+		}
+	}
+
+	// The sequence must be past the seeded ids
+	if err := roles.CreateRole(&models.Role{RoleName: "contract", RoleDescription: "contract test"}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+}
+
+func contractUser(t *testing.T, db *pgxpool.Pool, username, role string) *models.User {
+	t.Helper()
+
+	r, err := NewRoleRepository(db, "public").GetRoleByName(role)
+	if err != nil {
+		t.Fatalf("failed to get role %s: %v", role, err)
+	}
+	user, err := NewUserRepository(db, "public").CreateUser(context.Background(), &models.User{
+		Username:  username,
+		Fullname:  username,
+		Email:     username + "@example.com",
+		Password:  "not-a-real-hash",
+		RoleID:    r.RoleID,
+		IsActive:  true,
+		CreatedBy: "test",
+	})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+func contractCampaign(t *testing.T, db *pgxpool.Pool, recipientID *int64) *models.Campaigns {
+	t.Helper()
+
+	now := time.Now()
+	campaign := &models.Campaigns{
+		Title:       "Contract campaign",
+		Description: "Description",
+		Target:      100_000,
+		Currency:    "USD",
+		StartDate:   now.Add(-time.Hour),
+		EndDate:     now.Add(24 * time.Hour),
+		Status:      models.CampaignStatusActive,
+		RecipientID: recipientID,
+		CreatedBy:   "test",
+	}
+	if err := NewCampaignRepository(db, "public").CreateCampaign(context.Background(), campaign); err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	return campaign
+}
+
+func TestSchemaUsers(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	users := NewUserRepository(db, "public")
+	user := contractUser(t, db, "contract-user", models.RoleDonor)
+
+	if _, err := users.GetUserByName(user.Username); err != nil {
+		t.Errorf("GetUserByName: %v", err)
+	}
+	if _, err := users.GetUserByEmail(user.Email); err != nil {
+		t.Errorf("GetUserByEmail: %v", err)
+	}
+	if _, err := users.GetUserByID(ctx, user.UserID); err != nil {
+		t.Errorf("GetUserByID: %v", err)
+	}
+	if _, err := users.CheckUsernameExists(user.Username); err != nil {
+		t.Errorf("CheckUsernameExists: %v", err)
+	}
+	if _, err := users.CheckEmailExists(user.Email); err != nil {
+		t.Errorf("CheckEmailExists: %v", err)
+	}
+	user.PhoneNumber = "+100000000"
+	user.ModifiedBy = "test"
+	if err := users.UpdateUser(ctx, user); err != nil {
+		t.Errorf("UpdateUser: %v", err)
+	}
+	if err := users.UpdateUserPassword(ctx, user.UserID, "another-hash"); err != nil {
+		t.Errorf("UpdateUserPassword: %v", err)
+	}
+	if err := users.MarkEmailVerified(ctx, user.UserID); err != nil {
+		t.Errorf("MarkEmailVerified: %v", err)
+	}
+	if err := users.DeactivateUser(ctx, user.UserID, "test"); err != nil {
+		t.Errorf("DeactivateUser: %v", err)
+	}
+}
+
+func TestSchemaCampaignsAndDonations(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	now := time.Now()
+	campaigns := NewCampaignRepository(db, "public")
+	donations := NewDonationRepository(db, "public")
+	refunds := NewDonationRefundRepository(db, "public")
+	receipts := NewDonationReceiptRepository(db, "public")
+	analytics := NewAnalyticsRepository(db, "public")
+
+	recipient := contractUser(t, db, "contract-recipient", models.RoleRecipient)
+	donor := contractUser(t, db, "contract-donor", models.RoleDonor)
+	campaign := contractCampaign(t, db, &recipient.UserID)
+
+	if _, err := campaigns.GetCampaignByID(ctx, campaign.CampaignID); err != nil {
+		t.Errorf("GetCampaignByID: %v", err)
+	}
+	campaign.ShortDescription = "Short"
+	campaign.ModifiedBy = "test"
+	if err := campaigns.UpdateCampaign(ctx, campaign); err != nil {
+		t.Errorf("UpdateCampaign: %v", err)
+	}
+	if _, err := campaigns.ListActiveCampaigns(ctx); err != nil {
+		t.Errorf("ListActiveCampaigns: %v", err)
+	}
+	if _, err := campaigns.ListCampaigns(ctx, ""); err != nil {
+		t.Errorf("ListCampaigns: %v", err)
+	}
+	if _, err := campaigns.ListRecipientCampaigns(ctx, recipient.UserID); err != nil {
+		t.Errorf("ListRecipientCampaigns: %v", err)
+	}
+	if _, err := campaigns.ActivateScheduledCampaigns(ctx, now); err != nil {
+		t.Errorf("ActivateScheduledCampaigns: %v", err)
+	}
+	if _, err := campaigns.CloseEndedCampaigns(ctx, now); err != nil {
+		t.Errorf("CloseEndedCampaigns: %v", err)
+	}
+
+	donation := &models.Donation{
+		UserID:         donor.UserID,
+		CampaignID:     campaign.CampaignID,
+		Amount:         2500,
+		Currency:       "USD",
+		OriginalAmount: 2500,
+		ExchangeRate:   "1",
+		PaymentStatus:  models.PaymentStatusPending,
+		PaymentMethod:  "card",
+		TransactionID:  "contract-tx",
+		Message:        "Good luck",
+		DedicationType: models.DedicationHonor,
+		DedicationName: "A friend",
+		MessageStatus:  models.MessageStatusApproved,
+	}
+	if err := donations.CreateDonation(ctx, donation); err != nil {
+		t.Fatalf("CreateDonation: %v", err)
+	}
+	if err := donations.SetPaymentReference(ctx, donation.ID, "card", "contract-tx-2"); err != nil {
+		t.Errorf("SetPaymentReference: %v", err)
+	}
+	if err := donations.UpdatePaymentStatus(ctx, donation.ID, models.PaymentStatusPending, models.PaymentStatusPaid); err != nil {
+		t.Errorf("UpdatePaymentStatus: %v", err)
+	}
+	if err := campaigns.IncrementCurrentAmount(ctx, campaign.CampaignID, donation.Amount); err != nil {
+		t.Errorf("IncrementCurrentAmount: %v", err)
+	}
+	if got, err := donations.GetDonationByID(ctx, donation.ID); err != nil || got == nil {
+		t.Errorf("GetDonationByID = %v, %v", got, err)
+	}
+	if got, err := donations.GetDonationByTransactionID(ctx, "contract-tx-2"); err != nil || got == nil {
+		t.Errorf("GetDonationByTransactionID = %v, %v", got, err)
+	}
+	if err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := donations.LockDonation(ctx, donation.ID)
+		return err
+	}); err != nil {
+		t.Errorf("LockDonation: %v", err)
+	}
+	if _, err := donations.GetUserDonations(ctx, donor.UserID); err != nil {
+		t.Errorf("GetUserDonations: %v", err)
+	}
+	if _, err := donations.GetCampaignDonations(ctx, campaign.CampaignID); err != nil {
+		t.Errorf("GetCampaignDonations: %v", err)
+	}
+	if _, err := donations.GetAllDonations(ctx); err != nil {
+		t.Errorf("GetAllDonations: %v", err)
+	}
+	if _, err := donations.GetDonorIDsByYear(ctx, now.Year()); err != nil {
+		t.Errorf("GetDonorIDsByYear: %v", err)
+	}
+	if err := donations.UpdateMessageStatus(ctx, donation.ID, models.MessageStatusApproved); err != nil {
+		t.Errorf("UpdateMessageStatus: %v", err)
+	}
+	if _, err := donations.GetCampaignSupportMessages(ctx, campaign.CampaignID, 10, 0); err != nil {
+		t.Errorf("GetCampaignSupportMessages: %v", err)
+	}
+	if _, _, err := donations.GetCampaignPublicDonations(ctx, campaign.CampaignID, 10, 0); err != nil {
+		t.Errorf("GetCampaignPublicDonations: %v", err)
+	}
+	if _, err := donations.GetCampaignLeaderboard(ctx, campaign.CampaignID, nil, 10); err != nil {
+		t.Errorf("GetCampaignLeaderboard: %v", err)
+	}
+	if _, err := donations.GetCampaignStats(ctx, campaign.CampaignID); err != nil {
+		t.Errorf("GetCampaignStats: %v", err)
+	}
+	if _, err := donations.GetCampaignDailyTotals(ctx, campaign.CampaignID); err != nil {
+		t.Errorf("GetCampaignDailyTotals: %v", err)
+	}
+
+	refund := &models.DonationRefund{
+		DonationID:     donation.ID,
+		Amount:         500,
+		OriginalAmount: 500,
+		Currency:       "USD",
+		Reason:         "contract",
+		Status:         models.RefundStatusPending,
+		CreatedBy:      "test",
+	}
+	if err := refunds.CreateRefund(ctx, refund); err != nil {
+		t.Fatalf("CreateRefund: %v", err)
+	}
+	if err := refunds.UpdateRefundStatus(ctx, refund.ID, models.RefundStatusPending, models.RefundStatusCompleted); err != nil {
+		t.Errorf("UpdateRefundStatus: %v", err)
+	}
+	if _, err := refunds.GetDonationRefunds(ctx, donation.ID); err != nil {
+		t.Errorf("GetDonationRefunds: %v", err)
+	}
+	if _, _, err := refunds.GetRefundedTotals(ctx, donation.ID); err != nil {
+		t.Errorf("GetRefundedTotals: %v", err)
+	}
+	if _, err := refunds.GetUserRefundedTotals(ctx, donor.UserID); err != nil {
+		t.Errorf("GetUserRefundedTotals: %v", err)
+	}
+	if _, err := campaigns.RecomputeCurrentAmounts(ctx, &campaign.CampaignID); err != nil {
+		t.Errorf("RecomputeCurrentAmounts: %v", err)
+	}
+
+	sequence, err := receipts.NextSequenceNumber(ctx, now.Year())
+	if err != nil {
+		t.Fatalf("NextSequenceNumber: %v", err)
+	}
+	receipt := &models.DonationReceipt{
+		DonationID:     donation.ID,
+		ReceiptYear:    now.Year(),
+		SequenceNumber: sequence,
+		ReceiptNumber:  "CONTRACT-1",
+	}
+	if err := receipts.CreateReceipt(ctx, receipt); err != nil {
+		t.Errorf("CreateReceipt: %v", err)
+	}
+	if _, err := receipts.GetReceiptByDonationID(ctx, donation.ID); err != nil {
+		t.Errorf("GetReceiptByDonationID: %v", err)
+	}
+
+	from, to := now.AddDate(0, 0, -1), now.AddDate(0, 0, 1)
+	if _, err := analytics.GetPeriodTotals(ctx, from, to, nil, "day"); err != nil {
+		t.Errorf("GetPeriodTotals: %v", err)
+	}
+	if _, err := analytics.GetDonorActivity(ctx, from, to, nil, "day"); err != nil {
+		t.Errorf("GetDonorActivity: %v", err)
+	}
+	if _, err := analytics.GetTopCampaigns(ctx, from, to, nil, 10); err != nil {
+		t.Errorf("GetTopCampaigns: %v", err)
+	}
+	if _, err := analytics.GetDonorConversion(ctx, from, to, nil); err != nil {
+		t.Errorf("GetDonorConversion: %v", err)
+	}
+	if _, err := analytics.GetRetentionCohorts(ctx, from, to, nil); err != nil {
+		t.Errorf("GetRetentionCohorts: %v", err)
+	}
+
+	if err := campaigns.UpdateStatus(ctx, campaign.CampaignID, models.CampaignStatusArchived, "test"); err != nil {
+		t.Errorf("UpdateStatus: %v", err)
+	}
+}
+
+func TestSchemaRecurringDonations(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	now := time.Now()
+	recurringRepo := NewRecurringDonationRepository(db, "public")
+	donor := contractUser(t, db, "contract-recurring", models.RoleDonor)
+	campaign := contractCampaign(t, db, nil)
+
+	recurring := &models.RecurringDonation{
+		UserID:         donor.UserID,
+		CampaignID:     campaign.CampaignID,
+		Amount:         1000,
+		Currency:       "USD",
+		PaymentMethod:  "card",
+		Interval:       models.RecurringIntervalMonthly,
+		AnchorDay:      now.Day(),
+		NextChargeDate: now.Add(-time.Minute),
+		Status:         models.RecurringStatusActive,
+		CreatedBy:      donor.Username,
+	}
+	if err := recurringRepo.CreateRecurringDonation(ctx, recurring); err != nil {
+		t.Fatalf("CreateRecurringDonation: %v", err)
+	}
+	if got, err := recurringRepo.GetRecurringDonationByID(ctx, recurring.ID); err != nil || got == nil {
+		t.Errorf("GetRecurringDonationByID = %v, %v", got, err)
+	}
+	if _, err := recurringRepo.GetUserRecurringDonations(ctx, donor.UserID); err != nil {
+		t.Errorf("GetUserRecurringDonations: %v", err)
+	}
+	if err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := recurringRepo.LockDueRecurringDonations(ctx, now, nil, 10)
+		return err
+	}); err != nil {
+		t.Errorf("LockDueRecurringDonations: %v", err)
+	}
+	if err := recurringRepo.AdvanceNextChargeDate(ctx, recurring.ID, now, now.AddDate(0, 1, 0)); err != nil {
+		t.Errorf("AdvanceNextChargeDate: %v", err)
+	}
+	if err := recurringRepo.UpdateStatus(ctx, recurring.ID, models.RecurringStatusPaused, now.AddDate(0, 1, 0), "test"); err != nil {
+		t.Errorf("UpdateStatus: %v", err)
+	}
+}
+
+func TestSchemaPayments(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	now := time.Now()
+	donor := contractUser(t, db, "contract-payments", models.RoleDonor)
+
+	events := NewPaymentWebhookEventRepository(db, "public")
+	event := &models.PaymentWebhookEvent{
+		Provider:      "fake",
+		EventID:       "evt-1",
+		TransactionID: "tx-1",
+		Status:        models.PaymentStatusPaid,
+		Payload:       json.RawMessage(`{"status":"paid"}`),
+		Signature:     "signature",
+	}
+	if err := events.SaveEvent(ctx, event); err != nil {
+		t.Fatalf("SaveEvent: %v", err)
+	}
+	if err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := events.LockEvent(ctx, event.ID)
+		return err
+	}); err != nil {
+		t.Errorf("LockEvent: %v", err)
+	}
+	if err := events.MarkFailed(ctx, event.ID, "contract"); err != nil {
+		t.Errorf("MarkFailed: %v", err)
+	}
+	if err := events.MarkProcessed(ctx, event.ID); err != nil {
+		t.Errorf("MarkProcessed: %v", err)
+	}
+	if _, err := events.GetEventByID(ctx, event.ID); err != nil {
+		t.Errorf("GetEventByID: %v", err)
+	}
+	if _, err := events.ListEvents(ctx, 10, 0); err != nil {
+		t.Errorf("ListEvents: %v", err)
+	}
+
+	keys := NewIdempotencyKeyRepository(db, "public")
+	key := &models.IdempotencyKey{UserID: donor.UserID, Key: "contract", RequestHash: "hash", ExpiresAt: now.Add(time.Hour)}
+	if _, err := keys.Reserve(ctx, key); err != nil {
+		t.Errorf("Reserve: %v", err)
+	}
+	if err := keys.SaveResponse(ctx, donor.UserID, "contract", 201, []byte(`{}`)); err != nil {
+		t.Errorf("SaveResponse: %v", err)
+	}
+	if _, err := keys.GetKey(ctx, donor.UserID, "contract"); err != nil {
+		t.Errorf("GetKey: %v", err)
+	}
+	if err := keys.Release(ctx, donor.UserID, "contract"); err != nil {
+		t.Errorf("Release: %v", err)
+	}
+
+	rates := NewExchangeRateRepository(db, "public")
+	if err := rates.UpsertRate(ctx, &models.ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.1", CreatedBy: "test", ModifiedBy: "test"}); err != nil {
+		t.Fatalf("UpsertRate: %v", err)
+	}
+	if _, err := rates.GetRate(ctx, "EUR", "USD"); err != nil {
+		t.Errorf("GetRate: %v", err)
+	}
+	if _, err := rates.ListRates(ctx); err != nil {
+		t.Errorf("ListRates: %v", err)
+	}
+	if err := rates.DeleteRate(ctx, "EUR", "USD"); err != nil {
+		t.Errorf("DeleteRate: %v", err)
+	}
+
+	notifications := NewNotificationsRepository(db, "public")
+	if err := notifications.CreateNotification(&models.Notifications{UserID: donor.UserID, Title: "Thanks", Message: "Thanks", CreatedBy: "test"}); err != nil {
+		t.Errorf("CreateNotification: %v", err)
+	}
+	if _, err := notifications.GetUserNotifications(donor.UserID); err != nil {
+		t.Errorf("GetUserNotifications: %v", err)
+	}
+}
+
+func TestSchemaAccounts(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	now := time.Now()
+	user := contractUser(t, db, "contract-account", models.RoleDonor)
+
+	sessions := NewSessionRepository(db, "public")
+	session := &models.UserSession{UserID: user.UserID, UserAgent: "test", IPAddress: "127.0.0.1", ExpiresAt: now.Add(time.Hour)}
+	if err := sessions.CreateSession(ctx, session); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, err := sessions.GetSession(ctx, session.ID); err != nil {
+		t.Errorf("GetSession: %v", err)
+	}
+	if _, err := sessions.ListActiveSessions(ctx, user.UserID); err != nil {
+		t.Errorf("ListActiveSessions: %v", err)
+	}
+	if err := sessions.TouchSession(ctx, session.ID, "test", "127.0.0.1", now.Add(2*time.Hour)); err != nil {
+		t.Errorf("TouchSession: %v", err)
+	}
+	token := &models.RefreshToken{SessionID: session.ID, TokenHash: "refresh-hash", ExpiresAt: now.Add(time.Hour)}
+	if err := sessions.CreateRefreshToken(ctx, token); err != nil {
+		t.Fatalf("CreateRefreshToken: %v", err)
+	}
+	if err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := sessions.GetRefreshTokenForUpdate(ctx, "refresh-hash")
+		return err
+	}); err != nil {
+		t.Errorf("GetRefreshTokenForUpdate: %v", err)
+	}
+	if err := sessions.MarkRefreshTokenUsed(ctx, token.ID); err != nil {
+		t.Errorf("MarkRefreshTokenUsed: %v", err)
+	}
+	if _, err := sessions.RevokeSession(ctx, session.ID, user.UserID, "logout"); err != nil {
+		t.Errorf("RevokeSession: %v", err)
+	}
+	if _, err := sessions.RevokeUserSessions(ctx, user.UserID, "logout"); err != nil {
+		t.Errorf("RevokeUserSessions: %v", err)
+	}
+
+	resets := NewPasswordResetRepository(db, "public")
+	if err := resets.CreateToken(ctx, &models.PasswordResetToken{UserID: user.UserID, TokenHash: "reset-hash", ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("CreateToken (password reset): %v", err)
+	}
+	if err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := resets.GetTokenForUpdate(ctx, "reset-hash")
+		return err
+	}); err != nil {
+		t.Errorf("GetTokenForUpdate (password reset): %v", err)
+	}
+	if err := resets.InvalidateUserTokens(ctx, user.UserID); err != nil {
+		t.Errorf("InvalidateUserTokens (password reset): %v", err)
+	}
+
+	verifications := NewEmailVerificationRepository(db, "public")
+	if err := verifications.CreateToken(ctx, &models.EmailVerificationToken{UserID: user.UserID, TokenHash: "verify-hash", ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("CreateToken (email verification): %v", err)
+	}
+	if err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := verifications.GetTokenForUpdate(ctx, "verify-hash")
+		return err
+	}); err != nil {
+		t.Errorf("GetTokenForUpdate (email verification): %v", err)
+	}
+	if _, err := verifications.GetLatestTokenTime(ctx, user.UserID); err != nil {
+		t.Errorf("GetLatestTokenTime: %v", err)
+	}
+	if err := verifications.InvalidateUserTokens(ctx, user.UserID); err != nil {
+		t.Errorf("InvalidateUserTokens (email verification): %v", err)
+	}
+
+	role, err := NewRoleRepository(db, "public").GetRoleByName(models.RoleRecipient)
+	if err != nil {
+		t.Fatalf("failed to get role: %v", err)
+	}
+	invitations := NewInvitationRepository(db, "public")
+	invitation := &models.Invitation{Email: "invited@example.com", RoleID: role.RoleID, ExpiresAt: now.Add(time.Hour), CreatedBy: "test"}
+	if err := invitations.CreateInvitation(ctx, invitation); err != nil {
+		t.Fatalf("CreateInvitation: %v", err)
+	}
+	if got, err := invitations.GetInvitation(ctx, invitation.ID); err != nil || got == nil {
+		t.Errorf("GetInvitation = %v, %v", got, err)
+	}
+	if _, err := invitations.ListInvitations(ctx, 10, 0); err != nil {
+		t.Errorf("ListInvitations: %v", err)
+	}
+	if err := invitations.MarkAccepted(ctx, invitation.ID, user.UserID); err != nil {
+		t.Errorf("MarkAccepted: %v", err)
+	}
+	if err := invitations.RevokePendingForEmail(ctx, "invited@example.com", "test"); err != nil {
+		t.Errorf("RevokePendingForEmail: %v", err)
+	}
+	if _, err := invitations.RevokeInvitation(ctx, invitation.ID, "test"); err != nil {
+		t.Errorf("RevokeInvitation: %v", err)
+	}
+}
+
+func TestSchemaEmailOutbox(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	now := time.Now()
+	outbox := NewEmailOutboxRepository(db, "public")
+
+	email := &models.OutboxEmail{
+		Recipient:     "someone@example.com",
+		Subject:       "Hello",
+		TextBody:      "Hello",
+		HTMLBody:      "<p>Hello</p>",
+		Template:      "contract",
+		Locale:        "en",
+		Status:        models.EmailStatusPending,
+		NextAttemptAt: now,
+	}
+	if err := outbox.CreateEmail(ctx, email); err != nil {
+		t.Fatalf("CreateEmail: %v", err)
+	}
+	if err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := outbox.LockDueEmails(ctx, now, 10)
+		return err
+	}); err != nil {
+		t.Errorf("LockDueEmails: %v", err)
+	}
+	if err := outbox.MarkAttemptFailed(ctx, email.ID, models.EmailStatusFailed, "contract", now); err != nil {
+		t.Errorf("MarkAttemptFailed: %v", err)
+	}
+	if err := outbox.RequeueEmail(ctx, email.ID, now); err != nil {
+		t.Errorf("RequeueEmail: %v", err)
+	}
+	if err := outbox.MarkSent(ctx, email.ID, now); err != nil {
+		t.Errorf("MarkSent: %v", err)
+	}
+	got, err := outbox.GetEmail(ctx, email.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetEmail = %v, %v", got, err)
+	}
+	if got.TextBody != "" || got.HTMLBody != "" {
+		t.Errorf("sent email kept its bodies: %q, %q", got.TextBody, got.HTMLBody)
+	}
+	if _, err := outbox.ListEmails(ctx, "", 10, 0); err != nil {
+		t.Errorf("ListEmails: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"share-the-meal/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionRepositoryInterface interface {
+	CreateSession(ctx context.Context, session *models.UserSession) error
+	GetSession(ctx context.Context, id int64) (*models.UserSession, error)
+	ListActiveSessions(ctx context.Context, userID int64) ([]models.UserSession, error)
+	TouchSession(ctx context.Context, id int64, userAgent, ipAddress string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, id, userID int64, reason string) (bool, error)
+	RevokeUserSessions(ctx context.Context, userID int64, reason string) (int64, error)
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id int64) error
+}
+
+type SessionRepository struct {
+	db     *pgxpool.Pool
+	schema string
+}
+
+func NewSessionRepository(db *pgxpool.Pool, schema string) *SessionRepository {
+	return &SessionRepository{
+		db:     db,
+		schema: schema,
+	}
+}
+
+const sessionColumns = `
+	id, user_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+	created_at, last_used_at, expires_at, revoked_at, COALESCE(revoke_reason, '')
+`
+
+func scanSession(row pgx.Row, session *models.UserSession) error {
+	return row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&session.RevokeReason,
+	)
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *models.UserSession) error {
+	query := `
+		INSERT INTO user_sessions (user_id, user_agent, ip_address, created_at, last_used_at, expires_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $4, $5)
+		RETURNING ` + sessionColumns
+
+	return scanSession(conn(ctx, r.db).QueryRow(ctx, query,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		time.Now(),
+		session.ExpiresAt,
+	), session)
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, id int64) (*models.UserSession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE id = $1`
+
+	var session models.UserSession
+	if err := scanSession(conn(ctx, r.db).QueryRow(ctx, query, id), &session); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ListActiveSessions returns the sessions of a user that are neither revoked
+// nor expired, most recently used first
+func (r *SessionRepository) ListActiveSessions(ctx context.Context, userID int64) ([]models.UserSession, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_used_at DESC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.UserSession
+	for rows.Next() {
+		var session models.UserSession
+		if err := scanSession(rows, &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// TouchSession records a refresh and extends the session to the expiry of its newest token
+func (r *SessionRepository) TouchSession(ctx context.Context, id int64, userAgent, ipAddress string, expiresAt time.Time) error {
+	query := `
+		UPDATE user_sessions
+		SET last_used_at = $1,
+			expires_at = $2,
+			user_agent = COALESCE(NULLIF($3, ''), user_agent),
+			ip_address = COALESCE(NULLIF($4, ''), ip_address)
+		WHERE id = $5`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), expiresAt, userAgent, ipAddress, id)
+	return err
+}
+
+// RevokeSession revokes a session of userID. It returns false when the
+// session does not exist, belongs to someone else or is already revoked.
+func (r *SessionRepository) RevokeSession(ctx context.Context, id, userID int64, reason string) (bool, error) {
+	query := `
+		UPDATE user_sessions
+		SET revoked_at = $1, revoke_reason = $2
+		WHERE id = $3 AND user_id = $4 AND revoked_at IS NULL`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), reason, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID int64, reason string) (int64, error) {
+	query := `
+		UPDATE user_sessions
+		SET revoked_at = $1, revoke_reason = $2
+		WHERE user_id = $3 AND revoked_at IS NULL`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), reason, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *SessionRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (session_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		token.SessionID,
+		token.TokenHash,
+		time.Now(),
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetRefreshTokenForUpdate locks the token row so concurrent refreshes with
+// the same token are serialized and the second one sees it as used
+func (r *SessionRepository) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, token_hash, created_at, expires_at, used_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`
+
+	var token models.RefreshToken
+	err := conn(ctx, r.db).QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.TokenHash,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.UsedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *SessionRepository) MarkRefreshTokenUsed(ctx context.Context, id int64) error {
+	query := `UPDATE refresh_tokens SET used_at = $1 WHERE id = $2`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), id)
+	return err
+}
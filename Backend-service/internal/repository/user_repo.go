@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"share-the-meal/internal/models"
 	"time"
@@ -10,15 +11,20 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrUserNotFound is returned when no active user matches the lookup
+var ErrUserNotFound = errors.New("user not found")
+
 type UserRepositoryInterface interface {
 	GetUserByName(userName string) (*models.User, error)
 	GetUserByEmail(email string) (*models.User, error)
-	CreateUser(user *models.User) (*models.User, error)
-	UpdateUserPassword(userID int64, hashedPassword string) error
+	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
+	UpdateUserPassword(ctx context.Context, userID int64, hashedPassword string) error
 	CheckUsernameExists(username string) (bool, error)
 	CheckEmailExists(email string) (bool, error)
 	GetUserByID(ctx context.Context, userID int64) (*models.User, error)
 	UpdateUser(ctx context.Context, user *models.User) error
+	DeactivateUser(ctx context.Context, userID int64, modifiedBy string) error
+	MarkEmailVerified(ctx context.Context, userID int64) error
 }
 
 type UserRepository struct {
@@ -55,7 +61,7 @@ func (r *UserRepository) GetUserByName(userName string) (*models.User, error) {
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %v", err)
 	}
@@ -68,14 +74,15 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 		SELECT 
 			user_id, 
 			username, 
-			fullname,
+			COALESCE(fullname, ''),
 			email,
 			password,
 			role_id,
-			profile_picture,
-			phone_number,
-			address,
+			COALESCE(profile_picture, ''),
+			COALESCE(phone_number, ''),
+			COALESCE(address, ''),
 			is_active,
+			email_verified_at,
 			created_at,
 			modified_at
 		FROM users 
@@ -94,13 +101,14 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 		&user.PhoneNumber,
 		&user.Address,
 		&user.IsActive,
+		&user.EmailVerifiedAt,
 		&user.CreatedAt,
 		&user.ModifiedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %v", err)
 	}
@@ -108,14 +116,15 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) CreateUser(user *models.User) (*models.User, error) {
+// CreateUser joins the transaction in ctx, if any
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
 	query := `
         INSERT INTO users (
             username, fullname, email, password, role_id, 
             phone_number, address, created_by, created_at, 
-            modified_by, modified_at, is_active
+            modified_by, modified_at, is_active, email_verified_at
         ) VALUES (
-            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
         ) RETURNING user_id
     ` // Hanya return user_id
 
@@ -126,7 +135,7 @@ func (r *UserRepository) CreateUser(user *models.User) (*models.User, error) {
 	user.ModifiedBy = user.Username
 
 	// Eksekusi query
-	err := r.db.QueryRow(context.Background(), query,
+	err := conn(ctx, r.db).QueryRow(ctx, query,
 		user.Username,
 		user.Fullname,
 		user.Email,
@@ -139,6 +148,7 @@ func (r *UserRepository) CreateUser(user *models.User) (*models.User, error) {
 		user.ModifiedBy,
 		user.ModifiedAt,
 		user.IsActive,
+		user.EmailVerifiedAt,
 	).Scan(&user.UserID)
 
 	if err != nil {
@@ -148,14 +158,14 @@ func (r *UserRepository) CreateUser(user *models.User) (*models.User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) UpdateUserPassword(userID int64, hashedPassword string) error {
+func (r *UserRepository) UpdateUserPassword(ctx context.Context, userID int64, hashedPassword string) error {
 	query := `
 		UPDATE users 
 		SET password = $1, modified_at = $2 
 		WHERE user_id = $3 AND is_active = true
 	`
 
-	_, err := r.db.Exec(context.Background(), query, hashedPassword, time.Now(), userID)
+	_, err := conn(ctx, r.db).Exec(ctx, query, hashedPassword, time.Now(), userID)
 	if err != nil {
 		return fmt.Errorf("failed to update password: %v", err)
 	}
@@ -193,14 +203,15 @@ func (r *UserRepository) GetUserByID(ctx context.Context, userID int64) (*models
 		SELECT 
 			user_id, 
 			username, 
-			fullname,
+			COALESCE(fullname, ''),
 			email,
 			password,
 			role_id,
-			profile_picture,
-			phone_number,
-			address,
+			COALESCE(profile_picture, ''),
+			COALESCE(phone_number, ''),
+			COALESCE(address, ''),
 			is_active,
+			email_verified_at,
 			created_at,
 			modified_at
 		FROM users 
@@ -219,13 +230,14 @@ func (r *UserRepository) GetUserByID(ctx context.Context, userID int64) (*models
 		&user.PhoneNumber,
 		&user.Address,
 		&user.IsActive,
+		&user.EmailVerifiedAt,
 		&user.CreatedAt,
 		&user.ModifiedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %v", err)
 	}
@@ -254,3 +266,41 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user *models.User) erro
 	)
 	return err
 }
+
+// DeactivateUser hides the user from every lookup, which also blocks sign-in
+func (r *UserRepository) DeactivateUser(ctx context.Context, userID int64, modifiedBy string) error {
+	query := `
+		UPDATE users 
+		SET is_active = false, modified_by = $1, modified_at = $2
+		WHERE user_id = $3 AND is_active = true
+	`
+
+	tag, err := r.db.Exec(ctx, query, modifiedBy, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate user: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// MarkEmailVerified records that the user confirmed their email address
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID int64) error {
+	query := `
+		UPDATE users 
+		SET email_verified_at = COALESCE(email_verified_at, $1), modified_at = $1
+		WHERE user_id = $2 AND is_active = true
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
@@ -2,10 +2,14 @@ package routes
 
 import (
 	"net/http"
+	"share-the-meal/internal/config"
 	"share-the-meal/internal/handlers"
 	"share-the-meal/internal/middleware"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/services"
 
 	"share-the-meal/internal/utils"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,16 +19,27 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRoutes(r *gin.Engine, db *pgxpool.Pool, logger *zap.Logger, hub *utils.Hub) {
+func SetupRoutes(r *gin.Engine, db *pgxpool.Pool, logger *zap.Logger, hub *utils.Hub, gateway services.PaymentGateway, mailer services.Mailer) {
 	r.Use(gin.Recovery())
 
-	authHandler := handlers.NewAuthHandler(db, logger)
+	authHandler := handlers.NewAuthHandler(db, logger, mailer)
 	campaignHandler := handlers.NewCampaignHandler(db, logger)
-	donationHandler := handlers.NewDonationHandler(db, logger)
+	donationHandler := handlers.NewDonationHandler(db, logger, hub, gateway)
 	userHandler := handlers.NewUserHandler(db, logger)
-	cmsHandler := handlers.NewCMSHandler(db, logger)
+	cmsHandler := handlers.NewCMSHandler(db, logger, hub, gateway)
 	companyHandler := handlers.NewCompanyHandler(logger)
 	notificationHandler := handlers.NewNotificationHandler(db, logger)
+	paymentHandler := handlers.NewPaymentHandler(db, logger, hub, gateway)
+	recurringDonationHandler := handlers.NewRecurringDonationHandler(db, logger, hub, gateway)
+	analyticsHandler := handlers.NewAnalyticsHandler(db, logger)
+	recipientHandler := handlers.NewRecipientHandler(db, logger, hub, gateway)
+	emailHandler := handlers.NewEmailHandler(db, logger, mailer)
+	invitationHandler := handlers.NewInvitationHandler(db, logger, mailer)
+
+	cfg, _ := config.GetConfig()
+	idempotencyRepo := repository.NewIdempotencyKeyRepository(db, "public")
+	idempotency := middleware.IdempotencyMiddleware(idempotencyRepo, time.Duration(cfg.IdempotencyTTLHours)*time.Hour)
+	verifiedEmail := middleware.VerifiedEmailMiddleware(repository.NewUserRepository(db, "public"), cfg.EmailVerification.UnverifiedPolicy)
 
 	// WebSocket endpoint
 	r.GET("/ws", func(c *gin.Context) {
@@ -39,13 +54,18 @@ func SetupRoutes(r *gin.Engine, db *pgxpool.Pool, logger *zap.Logger, hub *utils
 	r.GET("/swagger.yaml", swaggerHandler.ServeSwaggerYAML)
 	r.GET("/docs", swaggerHandler.ServeSwaggerUI)
 
-r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	apiV1 := r.Group("/api/v1")
 	{
 		authRoutes := apiV1.Group("/auth-management")
 		{
 			authRoutes.POST("/sign-in", authHandler.SignInUser)
 			authRoutes.POST("/register", authHandler.RegisterUser)
+			authRoutes.GET("/verify-email", authHandler.VerifyEmail)
+			authRoutes.POST("/resend-verification", authHandler.ResendVerification)
+			authRoutes.POST("/accept-invitation", invitationHandler.AcceptInvitation)
+			authRoutes.POST("/refresh", authHandler.RefreshToken)
+			authRoutes.POST("/logout", authHandler.Logout)
 			authRoutes.POST("/forgot-password", authHandler.ForgetPassword)
 			authRoutes.PUT("/change-password", authHandler.ChangePassword)
 		}
@@ -55,9 +75,18 @@ r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 		{
 			publicRoutes.GET("/campaigns", campaignHandler.ListActiveCampaigns)
 			publicRoutes.GET("/campaigns/:id", campaignHandler.GetCampaignDetails)
+			publicRoutes.GET("/campaigns/:id/wall", donationHandler.GetCampaignSupportMessages)
+			publicRoutes.GET("/campaigns/:id/donations", donationHandler.GetCampaignPublicDonations)
+			publicRoutes.GET("/campaigns/:id/leaderboard", donationHandler.GetCampaignLeaderboard)
 			publicRoutes.GET("/company-profile", companyHandler.GetCompanyProfile)
 		}
 
+		// Payment provider callbacks, authenticated by signature
+		paymentRoutes := apiV1.Group("/payments")
+		{
+			paymentRoutes.POST("/webhook/:provider", paymentHandler.HandleWebhook)
+		}
+
 		// Authenticated routes
 		auth := apiV1.Group("")
 		auth.Use(middleware.AuthMiddleware())
@@ -68,13 +97,22 @@ r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 				userRoutes.GET("/profile", userHandler.GetUserProfile)
 				userRoutes.PUT("/profile", userHandler.UpdateUserProfile)
 				userRoutes.GET("/notifications", notificationHandler.GetUserNotifications)
+				userRoutes.GET("/donations/statement", donationHandler.GetDonationStatement)
+				userRoutes.GET("/sessions", userHandler.ListSessions)
+				userRoutes.DELETE("/sessions/:id", userHandler.RevokeSession)
 			}
 
 			// Donation routes
 			donationRoutes := auth.Group("/donations")
 			{
-				donationRoutes.POST("", donationHandler.CreateDonation)
+				donationRoutes.POST("", verifiedEmail, idempotency, donationHandler.CreateDonation)
 				donationRoutes.GET("", donationHandler.GetUserDonations)
+				donationRoutes.GET("/:id/receipt", donationHandler.GetDonationReceipt)
+				donationRoutes.POST("/recurring", verifiedEmail, recurringDonationHandler.CreateRecurringDonation)
+				donationRoutes.GET("/recurring", recurringDonationHandler.GetUserRecurringDonations)
+				donationRoutes.POST("/recurring/:id/pause", recurringDonationHandler.PauseRecurringDonation)
+				donationRoutes.POST("/recurring/:id/resume", recurringDonationHandler.ResumeRecurringDonation)
+				donationRoutes.POST("/recurring/:id/cancel", recurringDonationHandler.CancelRecurringDonation)
 			}
 
 			// Real-time notifications
@@ -85,16 +123,53 @@ r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 			})
 		}
 
+		// Recipient routes, scoped to the campaigns the recipient owns
+		recipient := apiV1.Group("/recipient")
+		recipient.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("recipient"))
+		{
+			recipient.GET("/campaigns", recipientHandler.ListCampaigns)
+			recipient.POST("/campaigns", recipientHandler.CreateCampaign)
+			recipient.GET("/campaigns/:id", recipientHandler.GetCampaign)
+			recipient.PUT("/campaigns/:id", recipientHandler.UpdateCampaign)
+			recipient.POST("/campaigns/:id/submit", recipientHandler.SubmitCampaign)
+			recipient.GET("/campaigns/:id/donations", recipientHandler.GetCampaignDonations)
+			recipient.GET("/campaigns/:id/stats", recipientHandler.GetCampaignStats)
+		}
+
 		// CMS routes (Admin only)
 		cms := apiV1.Group("/cms")
 		cms.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("superadmin"))
 		{
+			cms.GET("/campaigns", cmsHandler.ListCampaigns)
 			cms.POST("/campaigns", cmsHandler.CreateCampaign)
 			cms.PUT("/campaigns/:id", cmsHandler.UpdateCampaign)
+			cms.PUT("/campaigns/:id/status", cmsHandler.UpdateCampaignStatus)
 			cms.DELETE("/campaigns/:id", cmsHandler.DeleteCampaign)
 			cms.GET("/campaigns/:id/stats", cmsHandler.GetCampaignStats)
 			cms.GET("/donations", cmsHandler.ListAllDonations)
+			cms.POST("/donations/statements", cmsHandler.GenerateDonationStatements)
+			cms.PUT("/donations/:id/status", cmsHandler.UpdateDonationStatus)
+			cms.POST("/donations/:id/sync-payment", cmsHandler.SyncDonationPayment)
+			cms.PUT("/donations/:id/message-status", cmsHandler.UpdateDonationMessageStatus)
+			cms.POST("/donations/:id/refund", cmsHandler.RefundDonation)
+			cms.GET("/donations/:id/refunds", cmsHandler.ListDonationRefunds)
+			cms.GET("/exchange-rates", cmsHandler.ListExchangeRates)
+			cms.PUT("/exchange-rates", cmsHandler.SetExchangeRate)
+			cms.DELETE("/exchange-rates/:base/:quote", cmsHandler.DeleteExchangeRate)
+			cms.GET("/payments/webhook-events", paymentHandler.ListWebhookEvents)
+			cms.GET("/payments/webhook-events/:id", paymentHandler.GetWebhookEvent)
+			cms.GET("/emails", emailHandler.ListEmails)
+			cms.GET("/emails/:id", emailHandler.GetEmail)
+			cms.POST("/emails/:id/retry", emailHandler.RetryEmail)
+			cms.GET("/invitations", invitationHandler.ListInvitations)
+			cms.POST("/invitations", invitationHandler.CreateInvitation)
+			cms.POST("/invitations/:id/revoke", invitationHandler.RevokeInvitation)
 			cms.PUT("/company-profile", companyHandler.UpdateCompanyProfile)
+			cms.GET("/analytics/totals", analyticsHandler.GetDonationTotals)
+			cms.GET("/analytics/donors", analyticsHandler.GetDonorActivity)
+			cms.GET("/analytics/top-campaigns", analyticsHandler.GetTopCampaigns)
+			cms.GET("/analytics/conversion", analyticsHandler.GetDonorConversion)
+			cms.GET("/analytics/retention", analyticsHandler.GetRetention)
 		}
 	}
 
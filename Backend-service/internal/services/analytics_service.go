@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"share-the-meal/internal/dto/request"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/repository"
+	"time"
+)
+
+const (
+	defaultAnalyticsDays     = 30
+	defaultAnalyticsInterval = "day"
+	defaultTopCampaigns      = 10
+)
+
+var ErrInvalidAnalyticsRange = errors.New("from must not be after to")
+
+type AnalyticsService struct {
+	analyticsRepo repository.AnalyticsRepositoryInterface
+}
+
+func NewAnalyticsService(analyticsRepo repository.AnalyticsRepositoryInterface) *AnalyticsService {
+	return &AnalyticsService{analyticsRepo: analyticsRepo}
+}
+
+// analyticsRange is a filter with its defaults applied. to is exclusive.
+type analyticsRange struct {
+	from       time.Time
+	to         time.Time
+	campaignID *int64
+	interval   string
+}
+
+// resolveRange applies the filter defaults: to is today, from is 30 days
+// before to and the interval is a day. The end date is made exclusive.
+func resolveRange(filter request.AnalyticsFilter) (analyticsRange, error) {
+	to := filter.To
+	if to.IsZero() {
+		now := time.Now().UTC()
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	from := filter.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultAnalyticsDays)
+	}
+	if from.After(to) {
+		return analyticsRange{}, ErrInvalidAnalyticsRange
+	}
+
+	interval := filter.Interval
+	if interval == "" {
+		interval = defaultAnalyticsInterval
+	}
+
+	return analyticsRange{
+		from:       from,
+		to:         to.AddDate(0, 0, 1),
+		campaignID: filter.CampaignID,
+		interval:   interval,
+	}, nil
+}
+
+func (r analyticsRange) response(withInterval bool) response.AnalyticsRange {
+	res := response.AnalyticsRange{
+		From:       r.from.Format("2006-01-02"),
+		To:         r.to.AddDate(0, 0, -1).Format("2006-01-02"),
+		CampaignID: r.campaignID,
+	}
+	if withInterval {
+		res.Interval = r.interval
+	}
+	return res
+}
+
+// GetTotals returns the amount raised per period and currency
+func (s *AnalyticsService) GetTotals(ctx context.Context, filter request.AnalyticsFilter) (*response.AnalyticsTotalsResponse, error) {
+	rng, err := resolveRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.analyticsRepo.GetPeriodTotals(ctx, rng.from, rng.to, rng.campaignID, rng.interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donation totals: %w", err)
+	}
+
+	res := &response.AnalyticsTotalsResponse{
+		Range:   rng.response(true),
+		Periods: []response.PeriodTotalResponse{},
+	}
+	for _, t := range totals {
+		res.Periods = append(res.Periods, response.PeriodTotalResponse{
+			Period:        t.Period.Format("2006-01-02"),
+			Currency:      t.Currency,
+			Amount:        t.Amount,
+			DonationCount: t.DonationCount,
+			DonorCount:    t.DonorCount,
+		})
+	}
+
+	return res, nil
+}
+
+// GetDonorActivity returns new and returning donors per period
+func (s *AnalyticsService) GetDonorActivity(ctx context.Context, filter request.AnalyticsFilter) (*response.DonorActivityResponse, error) {
+	rng, err := resolveRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := s.analyticsRepo.GetDonorActivity(ctx, rng.from, rng.to, rng.campaignID, rng.interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donor activity: %w", err)
+	}
+
+	res := &response.DonorActivityResponse{
+		Range:   rng.response(true),
+		Periods: []response.DonorActivityPeriod{},
+	}
+	for _, a := range activity {
+		res.Periods = append(res.Periods, response.DonorActivityPeriod{
+			Period:          a.Period.Format("2006-01-02"),
+			NewDonors:       a.NewDonors,
+			ReturningDonors: a.ReturningDonors,
+		})
+	}
+
+	return res, nil
+}
+
+// GetTopCampaigns returns the campaigns that raised the most in range
+func (s *AnalyticsService) GetTopCampaigns(ctx context.Context, filter request.AnalyticsFilter) (*response.TopCampaignsResponse, error) {
+	rng, err := resolveRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultTopCampaigns
+	}
+
+	campaigns, err := s.analyticsRepo.GetTopCampaigns(ctx, rng.from, rng.to, rng.campaignID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top campaigns: %w", err)
+	}
+
+	return &response.TopCampaignsResponse{
+		Range:     rng.response(false),
+		Campaigns: campaigns,
+	}, nil
+}
+
+// GetDonorConversion returns how many of the users registered in range have donated
+func (s *AnalyticsService) GetDonorConversion(ctx context.Context, filter request.AnalyticsFilter) (*response.DonorConversionResponse, error) {
+	rng, err := resolveRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := s.analyticsRepo.GetDonorConversion(ctx, rng.from, rng.to, rng.campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donor conversion: %w", err)
+	}
+
+	return &response.DonorConversionResponse{
+		Range:           rng.response(false),
+		RegisteredUsers: conversion.RegisteredUsers,
+		Donors:          conversion.Donors,
+		ConversionRate:  percentage(conversion.Donors, conversion.RegisteredUsers),
+	}, nil
+}
+
+// GetRetention returns monthly retention for the donor cohorts that started in range
+func (s *AnalyticsService) GetRetention(ctx context.Context, filter request.AnalyticsFilter) (*response.RetentionResponse, error) {
+	rng, err := resolveRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := s.analyticsRepo.GetRetentionCohorts(ctx, rng.from, rng.to, rng.campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention cohorts: %w", err)
+	}
+
+	res := &response.RetentionResponse{
+		Range:   rng.response(false),
+		Cohorts: []response.CohortRetention{},
+	}
+	// Rows are ordered by cohort and offset, and offset 0 holds the cohort size
+	for _, a := range activity {
+		cohort := a.Cohort.Format("2006-01")
+		if len(res.Cohorts) == 0 || res.Cohorts[len(res.Cohorts)-1].Cohort != cohort {
+			res.Cohorts = append(res.Cohorts, response.CohortRetention{Cohort: cohort})
+		}
+		current := &res.Cohorts[len(res.Cohorts)-1]
+		if a.MonthOffset == 0 {
+			current.Size = a.Donors
+		}
+		current.Months = append(current.Months, response.CohortMonthRetention{
+			MonthOffset:   a.MonthOffset,
+			Donors:        a.Donors,
+			RetentionRate: percentage(a.Donors, current.Size),
+		})
+	}
+
+	return res, nil
+}
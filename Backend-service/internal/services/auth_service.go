@@ -1,34 +1,59 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
+	"net/url"
 	"share-the-meal/internal/dto/request"
 	"share-the-meal/internal/dto/response"
 	"share-the-meal/internal/models"
 	"share-the-meal/internal/repository"
 	"share-the-meal/internal/utils"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
 type AuthService struct {
-	userRepo repository.UserRepositoryInterface
-	roleRepo repository.RoleRepositoryInterface
-	jwtUtil  utils.JWTUtilInterface
+	userRepo       repository.UserRepositoryInterface
+	roleRepo       repository.RoleRepositoryInterface
+	resetRepo      repository.PasswordResetRepositoryInterface
+	txManager      repository.TxManagerInterface
+	emailService   *EmailService
+	verification   *EmailVerificationService
+	sessionService *SessionService
+	resetTTL       time.Duration
+	resetURL       string
 }
 
 func NewAuthService(
 	userRepo repository.UserRepositoryInterface,
 	roleRepo repository.RoleRepositoryInterface,
-	jwtUtil utils.JWTUtilInterface) *AuthService {
+	resetRepo repository.PasswordResetRepositoryInterface,
+	txManager repository.TxManagerInterface,
+	emailService *EmailService,
+	verification *EmailVerificationService,
+	sessionService *SessionService,
+	resetTTL time.Duration,
+	resetURL string) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
-		jwtUtil:  jwtUtil,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		resetRepo:      resetRepo,
+		txManager:      txManager,
+		emailService:   emailService,
+		verification:   verification,
+		sessionService: sessionService,
+		resetTTL:       resetTTL,
+		resetURL:       resetURL,
 	}
 }
 
-func (s *AuthService) Login(email, password string) (*response.SignInResponse, error) {
+func (s *AuthService) Login(ctx context.Context, email, password string, client SessionClient) (*response.SignInResponse, error) {
 	// Get user by username
 	user, err := s.userRepo.GetUserByEmail(email)
 	if err != nil {
@@ -43,29 +68,42 @@ func (s *AuthService) Login(email, password string) (*response.SignInResponse, e
 		return nil, errors.New("incorrect password")
 	}
 
-	// Generate JWT token
+	if s.verification.BlocksLogin(user) {
+		return nil, ErrEmailNotVerified
+	}
+
 	// Dapatkan nama role
 	role, err := s.roleRepo.GetRoleByID(user.RoleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get role: %v", err)
 	}
 
-	token, err := s.jwtUtil.GenerateJWT(user.Username, int64(user.UserID), string(user.RoleID))
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %v", err)
-	}
+	// Start a session with an access token and a refresh token
+	return s.sessionService.StartSession(ctx, user, role.RoleName, client)
+}
 
-	response := &response.SignInResponse{
-		UserID:   int64(user.UserID),
-		UserName: user.Username,
-		Role:     role.RoleName,
-		Token:    token,
-	}
+// Refresh rotates a refresh token into a new token pair
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, client SessionClient) (*response.SignInResponse, error) {
+	return s.sessionService.Refresh(ctx, refreshToken, client)
+}
 
-	return response, nil
+// VerifyEmail redeems the token of a verification link
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	return s.verification.Verify(ctx, token)
 }
 
-func (s *AuthService) Register(req request.RegisterRequest) (*response.RegisterResponse, error) {
+// ResendVerification mails a new verification link to an unverified account
+func (s *AuthService) ResendVerification(ctx context.Context, email, locale string) error {
+	return s.verification.Resend(ctx, email, locale)
+}
+
+// Logout ends the session of a refresh token
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	return s.sessionService.Logout(ctx, refreshToken)
+}
+
+// Register creates the account and mails a verification link in the language of locale
+func (s *AuthService) Register(ctx context.Context, req request.RegisterRequest, locale string) (*response.RegisterResponse, error) {
 	// Check if username already exists
 	usernameExists, err := s.userRepo.CheckUsernameExists(req.Username)
 	if err != nil {
@@ -84,6 +122,12 @@ func (s *AuthService) Register(req request.RegisterRequest) (*response.RegisterR
 		return nil, errors.New("email already exists")
 	}
 
+	// Self registration always creates a donor, other roles need an invitation
+	role, err := s.roleRepo.GetRoleByName(models.RoleDonor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donor role: %v", err)
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -96,50 +140,76 @@ func (s *AuthService) Register(req request.RegisterRequest) (*response.RegisterR
 		Fullname:    req.Fullname,
 		Email:       req.Email,
 		Password:    string(hashedPassword),
-		RoleID:      req.Role,
+		RoleID:      role.RoleID,
 		PhoneNumber: req.PhoneNumber,
 		Address:     req.Address,
 		IsActive:    true,
 	}
 
 	// Create user in database
-	createdUser, err := s.userRepo.CreateUser(user)
+	createdUser, err := s.userRepo.CreateUser(ctx, user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %v", err)
 	}
 
+	// The account exists either way, a failed email can be requested again
+	if err := s.verification.SendVerification(ctx, createdUser, locale); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", createdUser.UserID, err)
+	}
+
 	response := &response.RegisterResponse{
-		UserID:   int64(createdUser.UserID),
-		UserName: createdUser.Username,
-		Email:    createdUser.Email,
-		Fullname: createdUser.Fullname,
-		RoleID:   createdUser.RoleID,
+		UserID:        int64(createdUser.UserID),
+		UserName:      createdUser.Username,
+		Email:         createdUser.Email,
+		Fullname:      createdUser.Fullname,
+		RoleID:        createdUser.RoleID,
+		EmailVerified: createdUser.IsEmailVerified(),
 	}
 
 	return response, nil
 }
 
-func (s *AuthService) ForgetPassword(email string) (*response.Meta, error) {
+// ForgetPassword mails a single-use reset link in the language of locale
+func (s *AuthService) ForgetPassword(ctx context.Context, email, locale string) (*response.Meta, error) {
 	// Check if user exists
 	user, err := s.userRepo.GetUserByEmail(email)
 	if err != nil {
-		if err.Error() == "user not found" {
+		if errors.Is(err, repository.ErrUserNotFound) {
 			return nil, errors.New("email not found")
 		}
 		return nil, fmt.Errorf("failed to get user: %v", err)
 	}
 
-	// Generate reset token
-	jwtUtil := s.jwtUtil.(*utils.JWTUtil) // Type assertion
-	resetToken, err := jwtUtil.GenerateResetToken(int64(user.UserID), user.Email)
+	// Generate reset token, only its hash is stored
+	resetToken, err := utils.GenerateOpaqueToken()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate reset token: %v", err)
 	}
 
-	// TODO: Send email with reset token
-	// For now, we'll just return success
-	// In production, you would send an email with the reset link
-	fmt.Printf("Reset token for %s: %s\n", email, resetToken)
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Only the most recently requested link works
+		if err := s.resetRepo.InvalidateUserTokens(ctx, user.UserID); err != nil {
+			return err
+		}
+		if err := s.resetRepo.CreateToken(ctx, &models.PasswordResetToken{
+			UserID:    user.UserID,
+			TokenHash: utils.HashToken(resetToken),
+			ExpiresAt: time.Now().Add(s.resetTTL),
+		}); err != nil {
+			return err
+		}
+
+		_, err := s.emailService.Queue(ctx, user.Email, "password_reset", locale, map[string]interface{}{
+			"Name":             user.Fullname,
+			"Link":             s.resetURL + "?token=" + url.QueryEscape(resetToken),
+			"ExpiresInMinutes": int(s.resetTTL.Minutes()),
+			"Company":          utils.GetCompanyProfile(),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reset link: %v", err)
+	}
 
 	return &response.Meta{
 		Code:    200,
@@ -148,18 +218,42 @@ func (s *AuthService) ForgetPassword(email string) (*response.Meta, error) {
 	}, nil
 }
 
-func (s *AuthService) ChangePassword(userID int64, newPassword string) error {
+// ResetPassword redeems a reset token. The token and every other outstanding
+// token of the user stop working, and all sessions are signed out.
+func (s *AuthService) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
 	// Hash new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %v", err)
 	}
 
-	// Update password in database
-	err = s.userRepo.UpdateUserPassword(userID, string(hashedPassword))
-	if err != nil {
-		return fmt.Errorf("failed to update password: %v", err)
-	}
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		token, err := s.resetRepo.GetTokenForUpdate(ctx, utils.HashToken(resetToken))
+		if err != nil {
+			return fmt.Errorf("failed to get reset token: %v", err)
+		}
+		if token == nil || !token.IsUsable(time.Now()) {
+			return ErrInvalidResetToken
+		}
+
+		// Deactivated users cannot reset their password
+		if _, err := s.userRepo.GetUserByID(ctx, token.UserID); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		if err := s.resetRepo.InvalidateUserTokens(ctx, token.UserID); err != nil {
+			return fmt.Errorf("failed to invalidate reset tokens: %v", err)
+		}
+
+		// Update password in database
+		if err := s.userRepo.UpdateUserPassword(ctx, token.UserID, string(hashedPassword)); err != nil {
+			return fmt.Errorf("failed to update password: %v", err)
+		}
 
-	return nil
+		// Sign out every device that still knows the old password
+		return s.sessionService.RevokeUserSessions(ctx, token.UserID, models.SessionRevokedPasswordChange)
+	})
 }
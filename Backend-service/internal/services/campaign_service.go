@@ -2,12 +2,24 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"mime/multipart"
 	"share-the-meal/internal/dto/request"
 	"share-the-meal/internal/dto/response"
 	"share-the-meal/internal/models"
 	"share-the-meal/internal/repository"
 	"share-the-meal/internal/utils"
+	"time"
+)
+
+var (
+	ErrCurrencyLocked                  = errors.New("campaign currency cannot change after donations were received")
+	ErrInvalidCampaignDates            = errors.New("campaign end date must be after its start date")
+	ErrCampaignEnded                   = errors.New("campaign end date has passed")
+	ErrInvalidCampaignStatusTransition = errors.New("invalid campaign status transition")
+	ErrCampaignNotActive               = errors.New("campaign is not accepting donations")
+	ErrCampaignNotEditable             = errors.New("only draft campaigns can be edited")
 )
 
 type CampaignService struct {
@@ -22,53 +34,81 @@ func NewCampaignService(campaignRepo repository.CampaignRepositoryInterface, min
 	}
 }
 
-func (s *CampaignService) CreateCampaign(ctx context.Context, req request.CreateCampaignRequest, file *multipart.FileHeader) (*response.CampaignResponse, error) {
-	imageURL, err := s.minioUtil.UploadFile(ctx, file, "campaigns", req.Title)
-	if err != nil {
-		return nil, err
+// CreateCampaign creates a draft campaign, or publishes it straight away when
+// the request asks for scheduled or active. Published campaigns get the
+// status their dates call for.
+func (s *CampaignService) CreateCampaign(ctx context.Context, req request.CreateCampaignRequest, file *multipart.FileHeader, createdBy string) (*response.CampaignResponse, error) {
+	currency := utils.NormalizeCurrency(req.Currency)
+	if currency == "" {
+		currency = utils.DefaultCurrency()
+	}
+	if !utils.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
 	}
 
+	now := time.Now()
 	campaign := &models.Campaigns{
-		Title:       req.Title,
-		Description: req.Content,
-		Target:      req.Target,
-		ImageURL:    imageURL,
-		IsActive:    true,
+		Title:            req.Title,
+		Description:      req.Content,
+		ShortDescription: req.ShortDescription,
+		Target:           req.Target,
+		Currency:         currency,
+		StartDate:        req.StartDate,
+		EndDate:          req.EndDate,
+		Status:           models.CampaignStatusDraft,
+		RecipientID:      req.RecipientID,
+		CreatedBy:        createdBy,
+	}
+	if campaign.StartDate.IsZero() {
+		campaign.StartDate = now
+	}
+	if !campaign.EndDate.After(campaign.StartDate) {
+		return nil, ErrInvalidCampaignDates
+	}
+	if req.Status != "" && req.Status != models.CampaignStatusDraft {
+		if err := publish(campaign, now); err != nil {
+			return nil, err
+		}
+	}
+
+	imageURL, err := s.minioUtil.UploadFile(ctx, file, "campaigns", req.Title)
+	if err != nil {
+		return nil, err
 	}
+	campaign.ImageURL = imageURL
 
 	err = s.campaignRepo.CreateCampaign(ctx, campaign)
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.CampaignResponse{
-		CampaignID: campaign.CampaignID,
-		Title:      campaign.Title,
-		Content:    campaign.Description,
-		Target:     campaign.Target,
-		Current:    campaign.Current,
-		ImageUrl:   campaign.ImageURL,
-	}, nil
+	return newCampaignResponse(campaign), nil
 }
 
+// publish sets the status a campaign leaving draft should have at now
+func publish(campaign *models.Campaigns, now time.Time) error {
+	status := campaign.ScheduledStatus(now)
+	if status != models.CampaignStatusScheduled && status != models.CampaignStatusActive {
+		return ErrCampaignEnded
+	}
+	campaign.Status = status
+	return nil
+}
+
+// GetCampaignDetails returns a campaign as shown to the public. Drafts,
+// campaigns under review and archived campaigns are not visible.
 func (s *CampaignService) GetCampaignDetails(ctx context.Context, id int64) (*response.CampaignResponse, error) {
 	campaign, err := s.campaignRepo.GetCampaignByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if !campaign.IsPubliclyVisible() {
+		return nil, repository.ErrCampaignNotFound
+	}
 
-	return &response.CampaignResponse{
-		CampaignID:  campaign.CampaignID,
-		Title:       campaign.Title,
-		Description: campaign.Description,
-		Target:      campaign.Target,
-		Current:     campaign.Current,
-		ImageUrl:    campaign.ImageURL,
-	}, nil
+	return newCampaignResponse(campaign), nil
 }
 
-// Implement other methods
-
 func (s *CampaignService) ListActiveCampaigns(ctx context.Context) ([]*response.CampaignResponse, error) {
 	campaigns, err := s.campaignRepo.ListActiveCampaigns(ctx)
 	if err != nil {
@@ -76,15 +116,22 @@ func (s *CampaignService) ListActiveCampaigns(ctx context.Context) ([]*response.
 	}
 
 	var responses []*response.CampaignResponse
-	for _, c := range campaigns {
-		responses = append(responses, &response.CampaignResponse{
-			CampaignID: c.CampaignID,
-			Title:      c.Title,
-			Content:    c.Description,
-			Target:     c.Target,
-			Current:    c.Current,
-			ImageUrl:   c.ImageURL,
-		})
+	for i := range campaigns {
+		responses = append(responses, newCampaignResponse(&campaigns[i]))
+	}
+	return responses, nil
+}
+
+// ListCampaigns lists campaigns in every status for the CMS, optionally filtered by status
+func (s *CampaignService) ListCampaigns(ctx context.Context, status string) ([]*response.CampaignResponse, error) {
+	campaigns, err := s.campaignRepo.ListCampaigns(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []*response.CampaignResponse{}
+	for i := range campaigns {
+		responses = append(responses, newCampaignResponse(&campaigns[i]))
 	}
 	return responses, nil
 }
@@ -94,6 +141,7 @@ func (s *CampaignService) UpdateCampaign(
 	id int64,
 	req request.UpdateCampaignRequest,
 	file *multipart.FileHeader,
+	modifiedBy string,
 ) (*response.CampaignResponse, error) {
 	campaign, err := s.campaignRepo.GetCampaignByID(ctx, id)
 	if err != nil {
@@ -107,9 +155,38 @@ func (s *CampaignService) UpdateCampaign(
 	if req.Description != "" {
 		campaign.Description = req.Description
 	}
+	if req.ShortDescription != "" {
+		campaign.ShortDescription = req.ShortDescription
+	}
 	if req.Target > 0 {
 		campaign.Target = req.Target
 	}
+	if currency := utils.NormalizeCurrency(req.Currency); currency != "" && currency != campaign.Currency {
+		if !utils.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+		}
+		if campaign.Current != 0 {
+			return nil, ErrCurrencyLocked
+		}
+		campaign.Currency = currency
+	}
+	if req.RecipientID != nil {
+		campaign.RecipientID = req.RecipientID
+	}
+
+	// Moving the dates of a published campaign can open or close it right away
+	if !req.StartDate.IsZero() {
+		campaign.StartDate = req.StartDate
+	}
+	if !req.EndDate.IsZero() {
+		campaign.EndDate = req.EndDate
+	}
+	if !campaign.EndDate.After(campaign.StartDate) {
+		return nil, ErrInvalidCampaignDates
+	}
+	if campaign.IsPublished() {
+		campaign.Status = campaign.ScheduledStatus(time.Now())
+	}
 
 	// Handle new image upload
 	if file != nil {
@@ -120,20 +197,151 @@ func (s *CampaignService) UpdateCampaign(
 		campaign.ImageURL = imageURL
 	}
 
+	campaign.ModifiedBy = modifiedBy
 	err = s.campaignRepo.UpdateCampaign(ctx, campaign)
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.CampaignResponse{
-		CampaignID:  campaign.CampaignID,
-		Title:       campaign.Title,
-		Description: campaign.Description,
-		Target:      campaign.Target,
-		ImageUrl:    campaign.ImageURL,
-	}, nil
+	return newCampaignResponse(campaign), nil
+}
+
+// UpdateCampaignStatus moves a campaign to another status. Asking for
+// scheduled or active publishes the campaign with the status its dates call for.
+func (s *CampaignService) UpdateCampaignStatus(ctx context.Context, id int64, status string, modifiedBy string) (*response.CampaignResponse, error) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status == status {
+		return newCampaignResponse(campaign), nil
+	}
+
+	if !models.CanTransitionCampaignStatus(campaign.Status, status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidCampaignStatusTransition, campaign.Status, status)
+	}
+	if status == models.CampaignStatusScheduled || status == models.CampaignStatusActive {
+		if err := publish(campaign, time.Now()); err != nil {
+			return nil, err
+		}
+	} else {
+		campaign.Status = status
+	}
+
+	if err := s.campaignRepo.UpdateStatus(ctx, id, campaign.Status, modifiedBy); err != nil {
+		return nil, err
+	}
+	campaign.ModifiedBy = modifiedBy
+
+	return newCampaignResponse(campaign), nil
+}
+
+// ApplyLifecycle activates scheduled campaigns whose start date has been
+// reached and closes open campaigns whose end date has passed
+func (s *CampaignService) ApplyLifecycle(ctx context.Context, now time.Time) (activated, closed int64, err error) {
+	activated, err = s.campaignRepo.ActivateScheduledCampaigns(ctx, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to activate campaigns: %w", err)
+	}
+
+	closed, err = s.campaignRepo.CloseEndedCampaigns(ctx, now)
+	if err != nil {
+		return activated, 0, fmt.Errorf("failed to close campaigns: %w", err)
+	}
+
+	return activated, closed, nil
+}
+
+// CreateRecipientCampaign creates a draft campaign owned by recipientID
+func (s *CampaignService) CreateRecipientCampaign(ctx context.Context, req request.CreateCampaignRequest, file *multipart.FileHeader, recipientID int64, username string) (*response.CampaignResponse, error) {
+	req.Status = models.CampaignStatusDraft
+	req.RecipientID = &recipientID
+
+	return s.CreateCampaign(ctx, req, file, username)
+}
+
+func (s *CampaignService) ListRecipientCampaigns(ctx context.Context, recipientID int64) ([]*response.CampaignResponse, error) {
+	campaigns, err := s.campaignRepo.ListRecipientCampaigns(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []*response.CampaignResponse{}
+	for i := range campaigns {
+		responses = append(responses, newCampaignResponse(&campaigns[i]))
+	}
+	return responses, nil
+}
+
+// GetRecipientCampaign returns a campaign owned by recipientID. Campaigns of
+// other recipients are reported as not found.
+func (s *CampaignService) GetRecipientCampaign(ctx context.Context, id, recipientID int64) (*response.CampaignResponse, error) {
+	campaign, err := s.getOwnedCampaign(ctx, id, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCampaignResponse(campaign), nil
+}
+
+// UpdateRecipientCampaign edits a draft campaign owned by recipientID. Once
+// submitted, only a superadmin can change it.
+func (s *CampaignService) UpdateRecipientCampaign(ctx context.Context, id, recipientID int64, req request.UpdateCampaignRequest, file *multipart.FileHeader, username string) (*response.CampaignResponse, error) {
+	campaign, err := s.getOwnedCampaign(ctx, id, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != models.CampaignStatusDraft {
+		return nil, ErrCampaignNotEditable
+	}
+
+	req.RecipientID = nil
+	return s.UpdateCampaign(ctx, id, req, file, username)
+}
+
+// SubmitCampaign sends a draft campaign owned by recipientID for superadmin review
+func (s *CampaignService) SubmitCampaign(ctx context.Context, id, recipientID int64, username string) (*response.CampaignResponse, error) {
+	campaign, err := s.getOwnedCampaign(ctx, id, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	if !campaign.EndDate.After(time.Now()) {
+		return nil, ErrCampaignEnded
+	}
+
+	return s.UpdateCampaignStatus(ctx, id, models.CampaignStatusPendingReview, username)
+}
+
+func (s *CampaignService) getOwnedCampaign(ctx context.Context, id, recipientID int64) (*models.Campaigns, error) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !campaign.IsOwnedBy(recipientID) {
+		return nil, repository.ErrCampaignNotFound
+	}
+
+	return campaign, nil
 }
 
 func (s *CampaignService) DeleteCampaign(ctx context.Context, id int64) error {
 	return s.campaignRepo.DeleteCampaign(ctx, id)
 }
+
+func newCampaignResponse(c *models.Campaigns) *response.CampaignResponse {
+	return &response.CampaignResponse{
+		CampaignID:       c.CampaignID,
+		Title:            c.Title,
+		Content:          c.Description,
+		Description:      c.Description,
+		ShortDescription: c.ShortDescription,
+		Target:           c.Target,
+		Current:          c.Current,
+		Currency:         c.Currency,
+		ImageUrl:         c.ImageURL,
+		StartDate:        c.StartDate,
+		EndDate:          c.EndDate,
+		Status:           c.Status,
+		RecipientID:      c.RecipientID,
+	}
+}
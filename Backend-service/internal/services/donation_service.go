@@ -3,84 +3,606 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"share-the-meal/internal/dto/request"
 	"share-the-meal/internal/dto/response"
 	"share-the-meal/internal/models"
 	"share-the-meal/internal/repository"
 	"share-the-meal/internal/utils"
+	"strings"
 	"time"
 )
 
 type DonationService struct {
 	donationRepo     repository.DonationRepositoryInterface
+	refundRepo       repository.DonationRefundRepositoryInterface
 	campaignRepo     repository.CampaignRepositoryInterface
 	notificationRepo repository.NotificationsRepositoryInterface
+	txManager        repository.TxManagerInterface
+	gateway          PaymentGateway
+	rateService      *ExchangeRateService
 	hub              *utils.Hub
 }
 
 func NewDonationService(
 	donationRepo repository.DonationRepositoryInterface,
+	refundRepo repository.DonationRefundRepositoryInterface,
 	campaignRepo repository.CampaignRepositoryInterface,
 	notificationRepo repository.NotificationsRepositoryInterface,
+	txManager repository.TxManagerInterface,
+	gateway PaymentGateway,
+	rateService *ExchangeRateService,
 	hub *utils.Hub,
 ) *DonationService {
 	return &DonationService{
 		donationRepo:     donationRepo,
+		refundRepo:       refundRepo,
 		campaignRepo:     campaignRepo,
 		notificationRepo: notificationRepo,
+		txManager:        txManager,
+		gateway:          gateway,
+		rateService:      rateService,
 		hub:              hub,
 	}
 }
 
+var (
+	ErrDonationNotFound         = errors.New("donation not found")
+	ErrInvalidStatusTransition  = errors.New("invalid payment status transition")
+	ErrAmountTooSmall           = errors.New("amount is too small after currency conversion")
+	ErrAmountTooLarge           = errors.New("amount is too large after currency conversion")
+	ErrDonationNotRefundable    = errors.New("only paid donations can be refunded")
+	ErrRefundExceedsDonation    = errors.New("refund exceeds the refundable amount")
+	ErrDonationHasNoMessage     = errors.New("donation has no message")
+	ErrInvalidLeaderboardWindow = errors.New("invalid leaderboard window")
+)
+
 func (s *DonationService) CreateDonation(ctx context.Context, req request.DonationRequest, userID int64) (*response.DonationResponse, error) {
-	// Create donation
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, req.CampaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+	if !campaign.AcceptsDonations(time.Now()) {
+		return nil, fmt.Errorf("%w: campaign is %s", ErrCampaignNotActive, campaign.Status)
+	}
+
+	// Donors pay in their own currency, the campaign total is kept in the campaign currency
+	currency := utils.NormalizeCurrency(req.Currency)
+	if currency == "" {
+		currency = campaign.Currency
+	}
+	if !utils.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+
+	amount, rate, err := s.rateService.Convert(ctx, req.Amount, currency, campaign.Currency)
+	if errors.Is(err, models.ErrMoneyOutOfRange) {
+		return nil, ErrAmountTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, ErrAmountTooSmall
+	}
+
+	// New donations start pending and only count once they are paid
 	donation := &models.Donation{
-		UserID:      userID,
-		CampaignID:  req.CampaignID,
-		Amount:      req.Amount,
-		IsAnonymous: req.IsAnonymous,
+		UserID:         userID,
+		CampaignID:     req.CampaignID,
+		Amount:         amount,
+		Currency:       currency,
+		OriginalAmount: req.Amount,
+		ExchangeRate:   rate,
+		IsAnonymous:    req.IsAnonymous,
+		PaymentStatus:  models.PaymentStatusPending,
+		PaymentMethod:  req.PaymentMethod,
+
+		Message:             strings.TrimSpace(req.Message),
+		DedicationType:      req.DedicationType,
+		DedicationName:      strings.TrimSpace(req.DedicationName),
+		RecurringDonationID: req.RecurringDonationID,
+	}
+	if donation.HasMessage() {
+		donation.MessageStatus = moderateMessage(donation.Message, donation.DedicationName)
 	}
 
-	err := s.donationRepo.CreateDonation(ctx, donation)
+	err = s.donationRepo.CreateDonation(ctx, donation)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update campaign current amount
-	campaign, err := s.campaignRepo.GetCampaignByID(ctx, req.CampaignID)
+	// Without a key from the caller the donation itself identifies the charge,
+	// so a retried call for the same donation never charges twice
+	chargeKey := req.IdempotencyKey
+	if chargeKey == "" {
+		chargeKey = fmt.Sprintf("donation-%d", donation.ID)
+	}
+
+	charge, err := s.gateway.CreateCharge(ctx, PaymentChargeRequest{
+		DonationID:     donation.ID,
+		UserID:         userID,
+		CampaignID:     donation.CampaignID,
+		Amount:         donation.OriginalAmount,
+		Currency:       donation.Currency,
+		PaymentMethod:  donation.PaymentMethod,
+		Description:    fmt.Sprintf("Donation #%d", donation.ID),
+		IdempotencyKey: chargeKey,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get campaign: %w", err)
+		s.failDonation(ctx, donation.ID)
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	// The charge exists at the provider now, so the reference is stored even
+	// if the client has gone away in the meantime
+	err = s.donationRepo.SetPaymentReference(context.WithoutCancel(ctx), donation.ID, donation.PaymentMethod, charge.TransactionID)
+	if err != nil {
+		log.Printf("Failed to store transaction %s for donation %d: %v", charge.TransactionID, donation.ID, err)
+		s.failDonation(ctx, donation.ID)
+		return nil, fmt.Errorf("failed to store payment reference: %w", err)
+	}
+	donation.TransactionID = charge.TransactionID
+
+	// Some gateways settle synchronously
+	if charge.Status != models.PaymentStatusPending {
+		donation, err = s.applyPaymentStatus(ctx, donation.ID, charge.Status)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := newDonationResponse(donation)
+	res.CheckoutURL = charge.CheckoutURL
+	return res, nil
+}
+
+// failDonation marks a donation whose payment could not be set up as failed
+func (s *DonationService) failDonation(ctx context.Context, id int64) {
+	if _, err := s.applyPaymentStatus(context.WithoutCancel(ctx), id, models.PaymentStatusFailed); err != nil {
+		log.Printf("Failed to mark donation %d as failed: %v", id, err)
+	}
+}
+
+// moderateMessage rejects messages that contain a blocked word. Rejected
+// messages are kept for the CMS but never shown publicly.
+func moderateMessage(texts ...string) string {
+	for _, text := range texts {
+		if utils.ContainsBlockedWord(text) {
+			return models.MessageStatusRejected
+		}
+	}
+	return models.MessageStatusApproved
+}
+
+// UpdateMessageStatus lets the CMS approve or reject a donor message
+func (s *DonationService) UpdateMessageStatus(ctx context.Context, id int64, status string) (*response.DonationResponse, error) {
+	donation, err := s.donationRepo.GetDonationByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donation: %w", err)
+	}
+	if donation == nil {
+		return nil, ErrDonationNotFound
+	}
+	if !donation.HasMessage() {
+		return nil, ErrDonationHasNoMessage
+	}
+
+	if err := s.donationRepo.UpdateMessageStatus(ctx, id, status); err != nil {
+		return nil, err
+	}
+	donation.MessageStatus = status
+
+	return newDonationResponse(donation), nil
+}
+
+// getPublicCampaign returns the campaign when the public may see it, hidden
+// campaigns are reported as not found just like GetCampaignDetails does
+func (s *DonationService) getPublicCampaign(ctx context.Context, campaignID int64) (*models.Campaigns, error) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if !campaign.IsPubliclyVisible() {
+		return nil, repository.ErrCampaignNotFound
+	}
+	return campaign, nil
+}
+
+// GetCampaignSupportMessages returns a page of the campaign's wall of support
+func (s *DonationService) GetCampaignSupportMessages(ctx context.Context, campaignID int64, limit, offset int) ([]*response.SupportMessageResponse, error) {
+	if _, err := s.getPublicCampaign(ctx, campaignID); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.donationRepo.GetCampaignSupportMessages(ctx, campaignID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []*response.SupportMessageResponse{}
+	for _, m := range messages {
+		donorName := m.DonorName
+		if m.IsAnonymous {
+			donorName = "Anonymous"
+		}
+		responses = append(responses, &response.SupportMessageResponse{
+			DonorName:      donorName,
+			IsAnonymous:    m.IsAnonymous,
+			Message:        m.Message,
+			DedicationType: m.DedicationType,
+			DedicationName: m.DedicationName,
+			CreatedAt:      m.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// GetCampaignPublicDonations returns a page of the campaign's public donor feed
+func (s *DonationService) GetCampaignPublicDonations(ctx context.Context, campaignID int64, limit, offset int) (*response.PaginatedResponse, error) {
+	campaign, err := s.getPublicCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	return s.campaignDonationFeed(ctx, campaign, limit, offset)
+}
+
+// GetRecipientCampaignDonations returns the donor feed of a campaign in any
+// status. The caller must have checked that the recipient owns the campaign.
+func (s *DonationService) GetRecipientCampaignDonations(ctx context.Context, campaignID int64, limit, offset int) (*response.PaginatedResponse, error) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	return s.campaignDonationFeed(ctx, campaign, limit, offset)
+}
+
+func (s *DonationService) campaignDonationFeed(ctx context.Context, campaign *models.Campaigns, limit, offset int) (*response.PaginatedResponse, error) {
+	donations, total, err := s.donationRepo.GetCampaignPublicDonations(ctx, campaign.CampaignID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*response.PublicDonationResponse{}
+	for _, d := range donations {
+		donorName := d.DonorName
+		if d.IsAnonymous {
+			donorName = "Anonymous"
+		}
+		items = append(items, &response.PublicDonationResponse{
+			DonorName:   donorName,
+			IsAnonymous: d.IsAnonymous,
+			Amount:      d.Amount,
+			Currency:    campaign.Currency,
+			CreatedAt:   d.CreatedAt,
+		})
+	}
+
+	return &response.PaginatedResponse{Items: items, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// leaderboardWindows maps the accepted window values to how far back they reach
+var leaderboardWindows = map[string]time.Duration{
+	"24h":  24 * time.Hour,
+	"7d":   7 * 24 * time.Hour,
+	"30d":  30 * 24 * time.Hour,
+	"90d":  90 * 24 * time.Hour,
+	"365d": 365 * 24 * time.Hour,
+}
+
+// GetCampaignLeaderboard ranks the campaign's top donors within a time
+// window such as "7d", or over the whole campaign for "all"
+func (s *DonationService) GetCampaignLeaderboard(ctx context.Context, campaignID int64, window string, limit int) ([]*response.LeaderboardEntryResponse, error) {
+	var since *time.Time
+	if window != "all" {
+		d, ok := leaderboardWindows[window]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLeaderboardWindow, window)
+		}
+		t := time.Now().Add(-d)
+		since = &t
+	}
+
+	campaign, err := s.getPublicCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.donationRepo.GetCampaignLeaderboard(ctx, campaignID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []*response.LeaderboardEntryResponse{}
+	for i, e := range entries {
+		donorName := e.DonorName
+		if e.IsAnonymous {
+			donorName = "Anonymous"
+		}
+		responses = append(responses, &response.LeaderboardEntryResponse{
+			Rank:          i + 1,
+			DonorName:     donorName,
+			IsAnonymous:   e.IsAnonymous,
+			TotalAmount:   e.TotalAmount,
+			Currency:      campaign.Currency,
+			DonationCount: e.DonationCount,
+		})
+	}
+	return responses, nil
+}
+
+// SyncPaymentStatus asks the gateway for the current charge status and
+// applies it when it differs from the stored one.
+func (s *DonationService) SyncPaymentStatus(ctx context.Context, id int64) (*response.DonationResponse, error) {
+	donation, err := s.donationRepo.GetDonationByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donation: %w", err)
+	}
+	if donation == nil {
+		return nil, ErrDonationNotFound
+	}
+	if donation.TransactionID == "" {
+		return nil, fmt.Errorf("donation %d has no payment reference", id)
+	}
+
+	status, err := s.gateway.GetChargeStatus(ctx, donation.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment status: %w", err)
+	}
+
+	if status != donation.PaymentStatus {
+		donation, err = s.applyPaymentStatus(ctx, id, status)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newDonationResponse(donation), nil
+}
+
+// UpdatePaymentStatus moves a donation through its payment lifecycle and
+// keeps the campaign total in step with the settled donations. Refunds go
+// through RefundDonation so that a reversal entry is recorded.
+func (s *DonationService) UpdatePaymentStatus(ctx context.Context, id int64, status string) (*response.DonationResponse, error) {
+	donation, err := s.applyPaymentStatus(ctx, id, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDonationResponse(donation), nil
+}
+
+// RefundDonation refunds part or all of a paid donation through the payment
+// gateway. The donation itself is left untouched. A pending reversal entry is
+// recorded and the campaign total reduced before the provider is called, so
+// money never leaves without a record; the provider gets the refund ID as
+// idempotency key. If the provider refuses, the refund is marked failed and
+// the campaign total restored. A zero amount refunds whatever is left of the
+// donation.
+func (s *DonationService) RefundDonation(ctx context.Context, id int64, req request.RefundDonationRequest, createdBy string) (*response.DonationRefundResponse, error) {
+	var donation *models.Donation
+	var refund *models.DonationRefund
+	var remaining models.Money
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		donation, err = s.donationRepo.LockDonation(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get donation: %w", err)
+		}
+		if donation == nil {
+			return ErrDonationNotFound
+		}
+		if donation.PaymentStatus != models.PaymentStatusPaid {
+			return fmt.Errorf("%w: donation is %s", ErrDonationNotRefundable, donation.PaymentStatus)
+		}
+
+		refundedAmount, refundedOriginal, err := s.refundRepo.GetRefundedTotals(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get refunded totals: %w", err)
+		}
+		remainingAmount := donation.Amount - refundedAmount
+		remainingOriginal := donation.OriginalAmount - refundedOriginal
+
+		refund = &models.DonationRefund{
+			DonationID:     id,
+			OriginalAmount: req.Amount,
+			Currency:       donation.Currency,
+			Reason:         req.Reason,
+			Status:         models.RefundStatusPending,
+			CreatedBy:      createdBy,
+		}
+		if refund.OriginalAmount == 0 {
+			refund.OriginalAmount = remainingOriginal
+		}
+		if refund.OriginalAmount <= 0 || refund.OriginalAmount > remainingOriginal {
+			return fmt.Errorf("%w: %s %s left", ErrRefundExceedsDonation, remainingOriginal, donation.Currency)
+		}
+
+		// The final refund takes whatever is left so rounding never leaves a remainder
+		if refund.OriginalAmount == remainingOriginal {
+			refund.Amount = remainingAmount
+		} else {
+			rate, err := parseRate(donation.ExchangeRate)
+			if err != nil {
+				return err
+			}
+			converted, err := refund.OriginalAmount.Convert(rate)
+			if err != nil {
+				return err
+			}
+			refund.Amount = min(converted, remainingAmount)
+		}
+		remaining = remainingOriginal - refund.OriginalAmount
+
+		if err := s.refundRepo.CreateRefund(ctx, refund); err != nil {
+			return fmt.Errorf("failed to record refund: %w", err)
+		}
+		if err := s.campaignRepo.IncrementCurrentAmount(ctx, donation.CampaignID, -refund.Amount); err != nil {
+			return fmt.Errorf("failed to update campaign: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if donation.TransactionID != "" {
+		key := fmt.Sprintf("refund-%d", refund.ID)
+		if err := s.gateway.Refund(ctx, donation.TransactionID, refund.OriginalAmount, key); err != nil {
+			if failErr := s.failRefund(ctx, refund, donation.CampaignID); failErr != nil {
+				log.Printf("Failed to mark refund %d as failed: %v", refund.ID, failErr)
+			}
+			return nil, fmt.Errorf("failed to refund payment: %w", err)
+		}
+	}
+
+	// The money has moved, a pending refund still counts in every total
+	if err := s.refundRepo.UpdateRefundStatus(ctx, refund.ID, models.RefundStatusPending, models.RefundStatusCompleted); err != nil {
+		log.Printf("Failed to mark refund %d as completed: %v", refund.ID, err)
+	} else {
+		refund.Status = models.RefundStatusCompleted
+	}
+
+	s.notifyDonor(donation.UserID, "Donation Refunded",
+		fmt.Sprintf("%s %s of your donation has been refunded", donation.Currency, refund.OriginalAmount),
+		map[string]interface{}{
+			"type":        "refund",
+			"donation_id": donation.ID,
+			"amount":      refund.OriginalAmount,
+			"currency":    donation.Currency,
+		})
+
+	return newDonationRefundResponse(refund, remaining), nil
+}
+
+// failRefund marks a pending refund failed and gives its amount back to the campaign
+func (s *DonationService) failRefund(ctx context.Context, refund *models.DonationRefund, campaignID int64) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.refundRepo.UpdateRefundStatus(ctx, refund.ID, models.RefundStatusPending, models.RefundStatusFailed); err != nil {
+			return err
+		}
+		return s.campaignRepo.IncrementCurrentAmount(ctx, campaignID, refund.Amount)
+	})
+}
+
+func (s *DonationService) GetDonationRefunds(ctx context.Context, id int64) ([]*response.DonationRefundResponse, error) {
+	donation, err := s.donationRepo.GetDonationByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donation: %w", err)
+	}
+	if donation == nil {
+		return nil, ErrDonationNotFound
 	}
 
-	campaign.Current += req.Amount
-	err = s.campaignRepo.UpdateCampaign(ctx, campaign)
+	refunds, err := s.refundRepo.GetDonationRefunds(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := donation.OriginalAmount
+	responses := []*response.DonationRefundResponse{}
+	for i := range refunds {
+		if refunds[i].Status != models.RefundStatusFailed {
+			remaining -= refunds[i].OriginalAmount
+		}
+		responses = append(responses, newDonationRefundResponse(&refunds[i], remaining))
+	}
+	return responses, nil
+}
+
+// applyPaymentStatus records a status reported by the payment gateway
+func (s *DonationService) applyPaymentStatus(ctx context.Context, id int64, status string) (*models.Donation, error) {
+	var donation *models.Donation
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		donation, err = s.transitionPaymentStatus(ctx, id, status)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if donation.PaymentStatus == models.PaymentStatusPaid {
+		s.notifyDonationPaid(donation)
+	}
+
+	return donation, nil
+}
+
+// transitionPaymentStatus must run inside a transaction
+func (s *DonationService) transitionPaymentStatus(ctx context.Context, id int64, status string) (*models.Donation, error) {
+	donation, err := s.donationRepo.LockDonation(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donation: %w", err)
+	}
+	if donation == nil {
+		return nil, ErrDonationNotFound
+	}
+
+	from := donation.PaymentStatus
+	if !models.CanTransitionPaymentStatus(from, status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, from, status)
+	}
+
+	if err := s.donationRepo.UpdatePaymentStatus(ctx, id, from, status); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatusTransition, err)
+	}
+
+	switch {
+	case status == models.PaymentStatusPaid:
+		err = s.campaignRepo.IncrementCurrentAmount(ctx, donation.CampaignID, donation.Amount)
+	case from == models.PaymentStatusPaid:
+		// Partial refunds have already been taken off the campaign total
+		var refunded models.Money
+		refunded, _, err = s.refundRepo.GetRefundedTotals(ctx, id)
+		if err == nil {
+			err = s.campaignRepo.IncrementCurrentAmount(ctx, donation.CampaignID, -(donation.Amount - refunded))
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update campaign: %w", err)
 	}
 
-	// Send real-time notification
+	donation.PaymentStatus = status
+	return donation, nil
+}
+
+// notifyDonationPaid stores and pushes the donor notification in the background
+func (s *DonationService) notifyDonationPaid(donation *models.Donation) {
+	s.notifyDonor(donation.UserID, "Donation Successful",
+		fmt.Sprintf("Your donation of %s %s has been processed", donation.Currency, donation.OriginalAmount),
+		map[string]interface{}{
+			"type":     "donation",
+			"amount":   donation.OriginalAmount,
+			"currency": donation.Currency,
+			"message":  "Thank you for your donation!",
+		})
+}
+
+// notifyDonor stores a notification and pushes msg over WebSocket in the background
+func (s *DonationService) notifyDonor(userID int64, title, message string, msg map[string]interface{}) {
+	if s.notificationRepo == nil || s.hub == nil {
+		return
+	}
+
 	go func() {
 		notification := &models.Notifications{
 			UserID:    userID,
-			Title:     "Donation Successful",
-			Message:   fmt.Sprintf("Your donation of $%.2f has been processed", req.Amount),
+			Title:     title,
+			Message:   message,
 			IsRead:    false,
 			CreatedBy: "system",
 			CreatedAt: time.Now(),
 		}
-		
-		if err := s.notificationRepo.CreateNotification( notification); err != nil {
+
+		if err := s.notificationRepo.CreateNotification(notification); err != nil {
 			log.Printf("Failed to create notification: %v", err)
 		}
 
 		// Notify via WebSocket
-		msg := map[string]interface{}{
-			"type":    "donation",
-			"amount":  req.Amount,
-			"message": "Thank you for your donation!",
-		}
 		jsonMsg, err := json.Marshal(msg)
 		if err != nil {
 			log.Printf("Failed to marshal websocket message: %v", err)
@@ -88,55 +610,98 @@ func (s *DonationService) CreateDonation(ctx context.Context, req request.Donati
 		}
 		s.hub.NotifyUser(userID, jsonMsg)
 	}()
+}
 
+func newDonationResponse(d *models.Donation) *response.DonationResponse {
 	return &response.DonationResponse{
-		ID:          donation.ID,
-		Amount:      donation.Amount,
-		IsAnonymous: donation.IsAnonymous,
-		CreatedAt:   donation.CreatedAt,
-	}, nil
+		ID:             d.ID,
+		CampaignID:     d.CampaignID,
+		Amount:         d.Amount,
+		Currency:       d.Currency,
+		OriginalAmount: d.OriginalAmount,
+		ExchangeRate:   d.ExchangeRate,
+		IsAnonymous:    d.IsAnonymous,
+		PaymentStatus:  d.PaymentStatus,
+		PaymentMethod:  d.PaymentMethod,
+		TransactionID:  d.TransactionID,
+		Message:        d.Message,
+		DedicationType: d.DedicationType,
+		DedicationName: d.DedicationName,
+		MessageStatus:  d.MessageStatus,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+func newDonationRefundResponse(r *models.DonationRefund, remaining models.Money) *response.DonationRefundResponse {
+	return &response.DonationRefundResponse{
+		ID:              r.ID,
+		DonationID:      r.DonationID,
+		Amount:          r.Amount,
+		OriginalAmount:  r.OriginalAmount,
+		Currency:        r.Currency,
+		Reason:          r.Reason,
+		Status:          r.Status,
+		RemainingAmount: remaining,
+		CreatedBy:       r.CreatedBy,
+		CreatedAt:       r.CreatedAt,
+	}
 }
 
 // GetCampaignStats returns statistics for a campaign
-func (s *DonationService) GetCampaignStats(ctx context.Context, campaignID int64) (*response.CampaignResponse, error) {
-	// Get campaign details
-	_, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
+func (s *DonationService) GetCampaignStats(ctx context.Context, campaignID int64) (*response.CampaignStatsResponse, error) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get all donations for the campaign
-	donations, err := s.donationRepo.GetCampaignDonations(ctx, campaignID)
+	stats, err := s.donationRepo.GetCampaignStats(ctx, campaignID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get campaign stats: %w", err)
 	}
 
-	// Calculate statistics
-	totalDonations := 0.0
-	donorCount := make(map[int64]bool) // Track unique donors
-
-	for _, donation := range donations {
-		totalDonations += donation.Amount
-		donorCount[donation.UserID] = true
+	daily, err := s.donationRepo.GetCampaignDailyTotals(ctx, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily totals: %w", err)
 	}
 
-	// Prepare response
-	stats := &response.CampaignResponse{
-		CampaignID:    campaignID,
-		// CampaignTitle: campaign.Title,
-		// TargetAmount:  campaign.Target,
-		// CurrentAmount: campaign.Current,
-		// TotalDonors:   len(donorCount),
-		// TotalDonations: len(donations),
-		// TotalAmount:   totalDonations,
+	res := &response.CampaignStatsResponse{
+		CampaignID:             campaign.CampaignID,
+		Title:                  campaign.Title,
+		Currency:               campaign.Currency,
+		TargetAmount:           campaign.Target,
+		TotalRaised:            stats.TotalRaised,
+		PercentOfTarget:        percentage(int64(stats.TotalRaised), int64(campaign.Target)),
+		DonorCount:             stats.DonorCount,
+		DonationCount:          stats.DonationCount,
+		AverageGift:            stats.AverageGift,
+		MedianGift:             stats.MedianGift,
+		AnonymousDonationCount: stats.AnonymousDonationCount,
+		AnonymousAmount:        stats.AnonymousAmount,
+		AnonymousShare:         percentage(stats.AnonymousDonationCount, stats.DonationCount),
+		Daily:                  []response.DailyTotalResponse{},
+	}
+	for _, d := range daily {
+		res.Daily = append(res.Daily, response.DailyTotalResponse{
+			Date:          d.Date.Format("2006-01-02"),
+			Amount:        d.Amount,
+			DonationCount: d.DonationCount,
+		})
 	}
 
-	return stats, nil
+	return res, nil
+}
+
+// percentage returns part as a percentage of whole rounded to 2 decimal places
+func percentage(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*10000/float64(whole)) / 100
 }
 
 // GetUserDonations retrieves donations made by a specific user
 func (s *DonationService) GetUserDonations(ctx context.Context, userID int64) ([]models.Donation, error) {
-    return s.donationRepo.GetUserDonations(ctx, userID)
+	return s.donationRepo.GetUserDonations(ctx, userID)
 }
 
 func (s *DonationService) ListAllDonations(ctx context.Context) ([]*response.DonationResponse, error) {
@@ -146,15 +711,8 @@ func (s *DonationService) ListAllDonations(ctx context.Context) ([]*response.Don
 	}
 
 	var responses []*response.DonationResponse
-	for _, d := range donations {
-		responses = append(responses, &response.DonationResponse{
-			ID:          d.ID,
-			// UserID:      d.UserID,
-			// CampaignID:  d.CampaignID,
-			Amount:      d.Amount,
-			IsAnonymous: d.IsAnonymous,
-			CreatedAt:   d.CreatedAt,
-		})
+	for i := range donations {
+		responses = append(responses, newDonationResponse(&donations[i]))
 	}
 	return responses, nil
-}
\ No newline at end of file
+}
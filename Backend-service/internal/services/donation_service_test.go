@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"share-the-meal/internal/dto/request"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/testdb"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func newTestDonationService(db *pgxpool.Pool, gateway PaymentGateway) *DonationService {
+	return NewDonationService(
+		repository.NewDonationRepository(db, "public"),
+		repository.NewDonationRefundRepository(db, "public"),
+		repository.NewCampaignRepository(db, "public"),
+		nil,
+		repository.NewTxManager(db),
+		gateway,
+		NewExchangeRateService(repository.NewExchangeRateRepository(db, "public")),
+		nil,
+	)
+}
+
+func createTestDonor(t *testing.T, db *pgxpool.Pool, username string) *models.User {
+	t.Helper()
+
+	role, err := repository.NewRoleRepository(db, "public").GetRoleByName(models.RoleDonor)
+	if err != nil {
+		t.Fatalf("failed to get donor role: %v", err)
+	}
+	user, err := repository.NewUserRepository(db, "public").CreateUser(context.Background(), &models.User{
+		Username: username,
+		Fullname: username,
+		Email:    username + "@example.com",
+		Password: "not-a-real-hash",
+		RoleID:   role.RoleID,
+		IsActive: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+func createTestCampaign(t *testing.T, db *pgxpool.Pool) *models.Campaigns {
+	t.Helper()
+
+	now := time.Now()
+	campaign := &models.Campaigns{
+		Title:     "Test campaign",
+		Target:    1_000_000,
+		Currency:  "USD",
+		StartDate: now.Add(-time.Hour),
+		EndDate:   now.Add(24 * time.Hour),
+		Status:    models.CampaignStatusActive,
+		CreatedBy: "test",
+	}
+	if err := repository.NewCampaignRepository(db, "public").CreateCampaign(context.Background(), campaign); err != nil {
+		t.Fatalf("failed to create campaign: %v", err)
+	}
+	return campaign
+}
+
+// TestConcurrentDonationsKeepCampaignTotal creates and settles donations from
+// many goroutines at once, settling every pending donation twice, and checks
+// that current_amount ends up equal to the sum of the paid donations.
+func TestConcurrentDonationsKeepCampaignTotal(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	service := newTestDonationService(db, NewFakePaymentGateway())
+	donor := createTestDonor(t, db, "concurrent-donor")
+	campaign := createTestCampaign(t, db)
+
+	const donations = 40
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		pending []int64
+	)
+	errs := make(chan error, 3*donations)
+
+	for i := 0; i < donations; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Every other donation settles later, like a card that needs 3-D Secure
+			method := "card"
+			if i%2 == 1 {
+				method = FakeMethodPending
+			}
+			donation, err := service.CreateDonation(ctx, request.DonationRequest{
+				CampaignID:    campaign.CampaignID,
+				Amount:        models.Money(100 * (i + 1)),
+				PaymentMethod: method,
+			}, donor.UserID)
+			if err != nil {
+				errs <- fmt.Errorf("donation %d: %w", i, err)
+				return
+			}
+			if donation.PaymentStatus == models.PaymentStatusPending {
+				mu.Lock()
+				pending = append(pending, donation.ID)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Two settlements race for every pending donation, only one may count
+	for _, id := range pending {
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func(id int64) {
+				defer wg.Done()
+				_, err := service.UpdatePaymentStatus(ctx, id, models.PaymentStatusPaid)
+				if err != nil && !errors.Is(err, ErrInvalidStatusTransition) {
+					errs <- fmt.Errorf("settle donation %d: %w", id, err)
+				}
+			}(id)
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	var paidCount int
+	var paidTotal models.Money
+	err := db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(amount), 0)
+		FROM donations
+		WHERE campaign_id = $1 AND payment_status = $2
+	`, campaign.CampaignID, models.PaymentStatusPaid).Scan(&paidCount, &paidTotal)
+	if err != nil {
+		t.Fatalf("failed to sum donations: %v", err)
+	}
+
+	updated, err := repository.NewCampaignRepository(db, "public").GetCampaignByID(ctx, campaign.CampaignID)
+	if err != nil {
+		t.Fatalf("failed to get campaign: %v", err)
+	}
+
+	var expected models.Money
+	for i := 0; i < donations; i++ {
+		expected += models.Money(100 * (i + 1))
+	}
+	if paidCount != donations {
+		t.Errorf("paid donations = %d, want %d", paidCount, donations)
+	}
+	if paidTotal != expected {
+		t.Errorf("sum of paid donations = %s, want %s", paidTotal, expected)
+	}
+	if updated.Current != paidTotal {
+		t.Errorf("current_amount = %s, want the sum of paid donations %s", updated.Current, paidTotal)
+	}
+}
+
+// refusingRefundGateway is a fake provider that declines every refund
+type refusingRefundGateway struct {
+	*FakePaymentGateway
+}
+
+func (g refusingRefundGateway) Refund(ctx context.Context, transactionID string, amount models.Money, idempotencyKey string) error {
+	return errors.New("provider declined the refund")
+}
+
+func TestRefundIsRecordedBeforeTheProviderIsCalled(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	donor := createTestDonor(t, db, "refund-donor")
+	campaign := createTestCampaign(t, db)
+	campaignRepo := repository.NewCampaignRepository(db, "public")
+	refundRepo := repository.NewDonationRefundRepository(db, "public")
+
+	declining := newTestDonationService(db, refusingRefundGateway{NewFakePaymentGateway()})
+	donation, err := declining.CreateDonation(ctx, request.DonationRequest{
+		CampaignID:    campaign.CampaignID,
+		Amount:        5000,
+		PaymentMethod: "card",
+	}, donor.UserID)
+	if err != nil {
+		t.Fatalf("failed to create donation: %v", err)
+	}
+
+	// A declined refund stays on record as failed and the total is restored
+	if _, err := declining.RefundDonation(ctx, donation.ID, request.RefundDonationRequest{Amount: 2000}, "test"); err == nil {
+		t.Fatalf("refund succeeded although the provider declined it")
+	}
+	refunds, err := refundRepo.GetDonationRefunds(ctx, donation.ID)
+	if err != nil {
+		t.Fatalf("failed to get refunds: %v", err)
+	}
+	if len(refunds) != 1 || refunds[0].Status != models.RefundStatusFailed {
+		t.Fatalf("refunds after a declined refund = %+v, want one failed refund", refunds)
+	}
+	updated, err := campaignRepo.GetCampaignByID(ctx, campaign.CampaignID)
+	if err != nil {
+		t.Fatalf("failed to get campaign: %v", err)
+	}
+	if updated.Current != 5000 {
+		t.Errorf("current_amount after a declined refund = %s, want 50.00", updated.Current)
+	}
+
+	// A later refund of the full amount is not blocked by the failed one
+	gateway := NewFakePaymentGateway()
+	gateway.charges[donation.TransactionID] = &fakeCharge{status: models.PaymentStatusPaid, amount: 5000}
+	service := newTestDonationService(db, gateway)
+
+	refund, err := service.RefundDonation(ctx, donation.ID, request.RefundDonationRequest{}, "test")
+	if err != nil {
+		t.Fatalf("failed to refund donation: %v", err)
+	}
+	if refund.Status != models.RefundStatusCompleted || refund.OriginalAmount != 5000 || refund.RemainingAmount != 0 {
+		t.Errorf("refund = %+v, want a completed refund of 50.00 with nothing remaining", refund)
+	}
+	updated, err = campaignRepo.GetCampaignByID(ctx, campaign.CampaignID)
+	if err != nil {
+		t.Fatalf("failed to get campaign: %v", err)
+	}
+	if updated.Current != 0 {
+		t.Errorf("current_amount after the refund = %s, want 0.00", updated.Current)
+	}
+}
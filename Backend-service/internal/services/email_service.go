@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"time"
+)
+
+// emailBatchSize bounds the emails delivered by one run of the outbox job
+const emailBatchSize = 50
+
+const (
+	emailRetryBaseDelay = time.Minute
+	emailRetryMaxDelay  = time.Hour
+
+	// emailSendTimeout bounds one delivery, the email stays locked meanwhile
+	emailSendTimeout = 30 * time.Second
+)
+
+var (
+	ErrEmailNotFound  = errors.New("email not found")
+	ErrEmailNotFailed = errors.New("only failed emails can be retried")
+)
+
+// EmailService queues templated emails in the outbox and delivers them
+// through the configured mailer, retrying with exponential backoff
+type EmailService struct {
+	outboxRepo  repository.EmailOutboxRepositoryInterface
+	txManager   repository.TxManagerInterface
+	templates   *EmailTemplates
+	mailer      Mailer
+	maxAttempts int
+}
+
+func NewEmailService(
+	outboxRepo repository.EmailOutboxRepositoryInterface,
+	txManager repository.TxManagerInterface,
+	templates *EmailTemplates,
+	mailer Mailer,
+	maxAttempts int,
+) *EmailService {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &EmailService{
+		outboxRepo:  outboxRepo,
+		txManager:   txManager,
+		templates:   templates,
+		mailer:      mailer,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Queue renders a template and stores the email for delivery. It joins the
+// transaction in ctx, so nothing is sent if the caller rolls back.
+func (s *EmailService) Queue(ctx context.Context, to, template, locale string, data interface{}) (*models.OutboxEmail, error) {
+	msg, usedLocale, err := s.templates.Render(template, locale, data)
+	if err != nil {
+		return nil, err
+	}
+
+	email := &models.OutboxEmail{
+		Recipient: to,
+		Subject:   msg.Subject,
+		TextBody:  msg.TextBody,
+		HTMLBody:  msg.HTMLBody,
+		Template:  template,
+		Locale:    usedLocale,
+	}
+	if err := s.outboxRepo.CreateEmail(ctx, email); err != nil {
+		return nil, fmt.Errorf("failed to queue email: %w", err)
+	}
+
+	return email, nil
+}
+
+// ProcessOutbox delivers the emails that are due and returns how many were
+// sent. Each email is locked in its own transaction while it is sent.
+func (s *EmailService) ProcessOutbox(ctx context.Context, now time.Time) (int, error) {
+	sent := 0
+
+	for i := 0; i < emailBatchSize; i++ {
+		var email *models.OutboxEmail
+		err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			due, err := s.outboxRepo.LockDueEmails(ctx, now, 1)
+			if err != nil {
+				return fmt.Errorf("failed to get due emails: %w", err)
+			}
+			if len(due) == 0 {
+				return nil
+			}
+			email = &due[0]
+
+			return s.deliver(ctx, email)
+		})
+
+		if email == nil || err != nil {
+			return sent, err
+		}
+		if email.Status == models.EmailStatusSent {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// deliver sends one locked email and records the outcome on it. A delivery
+// failure is not returned as an error, it is stored for the next attempt.
+func (s *EmailService) deliver(ctx context.Context, email *models.OutboxEmail) error {
+	sendCtx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+	defer cancel()
+
+	sendErr := s.mailer.Send(sendCtx, EmailMessage{
+		To:       email.Recipient,
+		Subject:  email.Subject,
+		TextBody: email.TextBody,
+		HTMLBody: email.HTMLBody,
+	})
+
+	now := time.Now()
+	if sendErr == nil {
+		email.Status = models.EmailStatusSent
+		return s.outboxRepo.MarkSent(ctx, email.ID, now)
+	}
+
+	attempts := email.Attempts + 1
+	email.Status = models.EmailStatusPending
+	if attempts >= s.maxAttempts {
+		email.Status = models.EmailStatusFailed
+	}
+	log.Printf("Failed to send email %d (attempt %d of %d): %v", email.ID, attempts, s.maxAttempts, sendErr)
+
+	return s.outboxRepo.MarkAttemptFailed(ctx, email.ID, email.Status, sendErr.Error(), now.Add(emailRetryDelay(attempts)))
+}
+
+// emailRetryDelay doubles the wait after every failed attempt, up to an hour
+func emailRetryDelay(attempts int) time.Duration {
+	delay := emailRetryBaseDelay
+	for i := 1; i < attempts && delay < emailRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > emailRetryMaxDelay {
+		delay = emailRetryMaxDelay
+	}
+	return delay
+}
+
+func (s *EmailService) ListEmails(ctx context.Context, status string, limit, offset int) ([]models.OutboxEmail, error) {
+	emails, err := s.outboxRepo.ListEmails(ctx, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list emails: %w", err)
+	}
+	return emails, nil
+}
+
+func (s *EmailService) GetEmail(ctx context.Context, id int64) (*models.OutboxEmail, error) {
+	email, err := s.outboxRepo.GetEmail(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email: %w", err)
+	}
+	if email == nil {
+		return nil, ErrEmailNotFound
+	}
+	return email, nil
+}
+
+// RetryEmail puts a failed email back in the queue with a fresh set of attempts
+func (s *EmailService) RetryEmail(ctx context.Context, id int64) (*models.OutboxEmail, error) {
+	email, err := s.GetEmail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if email.Status != models.EmailStatusFailed {
+		return nil, ErrEmailNotFailed
+	}
+
+	if err := s.outboxRepo.RequeueEmail(ctx, id, time.Now()); err != nil {
+		if err.Error() == "email not found" {
+			return nil, ErrEmailNotFailed
+		}
+		return nil, fmt.Errorf("failed to requeue email: %w", err)
+	}
+
+	return s.GetEmail(ctx, id)
+}
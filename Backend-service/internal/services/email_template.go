@@ -0,0 +1,118 @@
+package services
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// EmailTemplates renders emails from TemplateDir. Every email has a subject,
+// a text and an optional HTML part per locale, for example
+// email_password_reset.en.subject.tmpl, email_password_reset.en.text.tmpl and
+// email_password_reset.en.html.tmpl. Locales without their own files fall
+// back to the default locale.
+type EmailTemplates struct {
+	dir           string
+	defaultLocale string
+}
+
+func NewEmailTemplates(dir, defaultLocale string) *EmailTemplates {
+	return &EmailTemplates{dir: dir, defaultLocale: NormalizeLocale(defaultLocale, "en")}
+}
+
+// Render returns the rendered message without a recipient, together with the
+// locale that was actually used
+func (t *EmailTemplates) Render(name, locale string, data interface{}) (*EmailMessage, string, error) {
+	locale = t.resolveLocale(name, NormalizeLocale(locale, t.defaultLocale))
+
+	subject, err := t.renderText(name, locale, "subject", data)
+	if err != nil {
+		return nil, "", err
+	}
+	text, err := t.renderText(name, locale, "text", data)
+	if err != nil {
+		return nil, "", err
+	}
+	html, err := t.renderHTML(name, locale, data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &EmailMessage{
+		Subject:  strings.TrimSpace(subject),
+		TextBody: text,
+		HTMLBody: html,
+	}, locale, nil
+}
+
+func (t *EmailTemplates) path(name, locale, part string) string {
+	return filepath.Join(t.dir, fmt.Sprintf("email_%s.%s.%s.tmpl", name, locale, part))
+}
+
+// resolveLocale picks the default locale when the requested one has no text template
+func (t *EmailTemplates) resolveLocale(name, locale string) string {
+	if _, err := os.Stat(t.path(name, locale, "text")); err != nil {
+		return t.defaultLocale
+	}
+	return locale
+}
+
+func (t *EmailTemplates) renderText(name, locale, part string, data interface{}) (string, error) {
+	path := t.path(name, locale, part)
+	tmpl, err := template.ParseFiles(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to load email template %s: %w", filepath.Base(path), err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render email template %s: %w", filepath.Base(path), err)
+	}
+	return buf.String(), nil
+}
+
+// renderHTML returns an empty body when the email has no HTML template
+func (t *EmailTemplates) renderHTML(name, locale string, data interface{}) (string, error) {
+	path := t.path(name, locale, "html")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	tmpl, err := htmltemplate.ParseFiles(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to load email template %s: %w", filepath.Base(path), err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render email template %s: %w", filepath.Base(path), err)
+	}
+	return buf.String(), nil
+}
+
+// NormalizeLocale reduces a locale or an Accept-Language header such as
+// "id-ID,id;q=0.9,en;q=0.8" to its primary language, "id"
+func NormalizeLocale(value, fallback string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexAny(value, ",;"); i >= 0 {
+		value = value[:i]
+	}
+	if i := strings.IndexAny(value, "-_"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if value == "" || value == "*" || len(value) > 8 {
+		return fallback
+	}
+	for _, r := range value {
+		if r < 'a' || r > 'z' {
+			return fallback
+		}
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+	"time"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrVerificationThrottled    = errors.New("a verification email was sent recently, please wait before requesting another one")
+	ErrEmailNotVerified         = errors.New("email address is not verified")
+)
+
+// EmailVerificationService confirms that users own the email address they
+// registered with, and decides what unverified accounts may do
+type EmailVerificationService struct {
+	userRepo         repository.UserRepositoryInterface
+	verificationRepo repository.EmailVerificationRepositoryInterface
+	txManager        repository.TxManagerInterface
+	emailService     *EmailService
+	cfg              config.EmailVerificationConfig
+}
+
+func NewEmailVerificationService(
+	userRepo repository.UserRepositoryInterface,
+	verificationRepo repository.EmailVerificationRepositoryInterface,
+	txManager repository.TxManagerInterface,
+	emailService *EmailService,
+	cfg config.EmailVerificationConfig,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		txManager:        txManager,
+		emailService:     emailService,
+		cfg:              cfg,
+	}
+}
+
+// BlocksLogin reports whether the policy keeps the user from signing in
+func (s *EmailVerificationService) BlocksLogin(user *models.User) bool {
+	return s.cfg.UnverifiedPolicy == config.UnverifiedBlockLogin && !user.IsEmailVerified()
+}
+
+// SendVerification mails a new verification link, replacing any earlier one
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *models.User, locale string) error {
+	verificationToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	ttl := time.Duration(s.cfg.TTLHours) * time.Hour
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.verificationRepo.InvalidateUserTokens(ctx, user.UserID); err != nil {
+			return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+		}
+		if err := s.verificationRepo.CreateToken(ctx, &models.EmailVerificationToken{
+			UserID:    user.UserID,
+			TokenHash: utils.HashToken(verificationToken),
+			ExpiresAt: time.Now().Add(ttl),
+		}); err != nil {
+			return fmt.Errorf("failed to store verification token: %w", err)
+		}
+
+		_, err := s.emailService.Queue(ctx, user.Email, "verify_email", locale, map[string]interface{}{
+			"Name":           user.Fullname,
+			"Link":           s.cfg.URL + "?token=" + url.QueryEscape(verificationToken),
+			"ExpiresInHours": s.cfg.TTLHours,
+			"Company":        utils.GetCompanyProfile(),
+		})
+		return err
+	})
+}
+
+// Verify redeems a verification token and marks the email as verified
+func (s *EmailVerificationService) Verify(ctx context.Context, verificationToken string) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		token, err := s.verificationRepo.GetTokenForUpdate(ctx, utils.HashToken(verificationToken))
+		if err != nil {
+			return fmt.Errorf("failed to get verification token: %w", err)
+		}
+		if token == nil || !token.IsUsable(time.Now()) {
+			return ErrInvalidVerificationToken
+		}
+
+		if err := s.userRepo.MarkEmailVerified(ctx, token.UserID); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return ErrInvalidVerificationToken
+			}
+			return err
+		}
+
+		return s.verificationRepo.InvalidateUserTokens(ctx, token.UserID)
+	})
+}
+
+// Resend mails a new verification link, at most once per resend interval
+func (s *EmailVerificationService) Resend(ctx context.Context, email, locale string) error {
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return errors.New("email not found")
+		}
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	latest, err := s.verificationRepo.GetLatestTokenTime(ctx, user.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to check verification tokens: %w", err)
+	}
+	if latest != nil && time.Since(*latest) < time.Duration(s.cfg.ResendSeconds)*time.Second {
+		return ErrVerificationThrottled
+	}
+
+	return s.SendVerification(ctx, user, locale)
+}
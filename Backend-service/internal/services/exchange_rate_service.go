@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"share-the-meal/internal/dto/request"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+)
+
+// exchangeRatePrecision matches the scale of exchange_rates.rate
+const exchangeRatePrecision = 10
+
+var (
+	ErrUnsupportedCurrency  = errors.New("unsupported currency")
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+	ErrInvalidExchangeRate  = errors.New("invalid exchange rate")
+)
+
+type ExchangeRateService struct {
+	rateRepo repository.ExchangeRateRepositoryInterface
+}
+
+func NewExchangeRateService(rateRepo repository.ExchangeRateRepositoryInterface) *ExchangeRateService {
+	return &ExchangeRateService{rateRepo: rateRepo}
+}
+
+// Convert converts amount from one currency into another using the stored
+// rate, falling back to the inverse of the opposite pair. It returns the
+// converted amount together with the rate that was applied.
+func (s *ExchangeRateService) Convert(ctx context.Context, amount models.Money, from, to string) (models.Money, string, error) {
+	if from == to {
+		return amount, "1", nil
+	}
+
+	rate, err := s.lookupRate(ctx, from, to)
+	if err != nil {
+		return 0, "", err
+	}
+
+	// Apply the rate exactly as it is stored on the donation
+	snapshot := rate.FloatString(exchangeRatePrecision)
+	applied, err := parseRate(snapshot)
+	if err != nil {
+		return 0, "", err
+	}
+
+	converted, err := amount.Convert(applied)
+	if err != nil {
+		return 0, "", err
+	}
+	return converted, snapshot, nil
+}
+
+func (s *ExchangeRateService) lookupRate(ctx context.Context, from, to string) (*big.Rat, error) {
+	stored, err := s.rateRepo.GetRate(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	if stored != nil {
+		return parseRate(stored.Rate)
+	}
+
+	inverse, err := s.rateRepo.GetRate(ctx, to, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	if inverse == nil {
+		return nil, fmt.Errorf("%w: %s to %s", ErrExchangeRateNotFound, from, to)
+	}
+
+	rate, err := parseRate(inverse.Rate)
+	if err != nil {
+		return nil, err
+	}
+	return rate.Inv(rate), nil
+}
+
+func parseRate(value string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidExchangeRate, value)
+	}
+	return rate, nil
+}
+
+func (s *ExchangeRateService) SetRate(ctx context.Context, req request.SetExchangeRateRequest, modifiedBy string) (*models.ExchangeRate, error) {
+	base := utils.NormalizeCurrency(req.BaseCurrency)
+	quote := utils.NormalizeCurrency(req.QuoteCurrency)
+	if !utils.IsSupportedCurrency(base) || !utils.IsSupportedCurrency(quote) {
+		return nil, ErrUnsupportedCurrency
+	}
+	if base == quote {
+		return nil, fmt.Errorf("%w: base and quote currency must differ", ErrInvalidExchangeRate)
+	}
+
+	rate, err := parseRate(req.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeRate := &models.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          rate.FloatString(exchangeRatePrecision),
+		ModifiedBy:    modifiedBy,
+	}
+	if err := s.rateRepo.UpsertRate(ctx, exchangeRate); err != nil {
+		return nil, err
+	}
+
+	return exchangeRate, nil
+}
+
+func (s *ExchangeRateService) ListRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	return s.rateRepo.ListRates(ctx)
+}
+
+func (s *ExchangeRateService) DeleteRate(ctx context.Context, base, quote string) error {
+	return s.rateRepo.DeleteRate(ctx, utils.NormalizeCurrency(base), utils.NormalizeCurrency(quote))
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/dto/request"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidInvitation      = errors.New("invalid or expired invitation")
+	ErrInvitationNotFound     = errors.New("invitation not found")
+	ErrInvitationNotPending   = errors.New("invitation was already accepted or revoked")
+	ErrInvalidInvitationRole  = errors.New("invitations can only be issued for the recipient or superadmin role")
+	ErrEmailAlreadyRegistered = errors.New("email already exists")
+	ErrUsernameAlreadyTaken   = errors.New("username already exists")
+)
+
+// invitationTokenPrefix keeps invitation signatures apart from anything else
+// signed with the same secret
+const invitationTokenPrefix = "invitation:"
+
+// InvitationService lets superadmins create recipient and superadmin accounts.
+// The invitee gets a signed, expiring link; whether it was accepted or revoked
+// is kept on the invitation row.
+type InvitationService struct {
+	invitationRepo repository.InvitationRepositoryInterface
+	userRepo       repository.UserRepositoryInterface
+	roleRepo       repository.RoleRepositoryInterface
+	txManager      repository.TxManagerInterface
+	emailService   *EmailService
+	cfg            config.InvitationConfig
+}
+
+func NewInvitationService(
+	invitationRepo repository.InvitationRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	roleRepo repository.RoleRepositoryInterface,
+	txManager repository.TxManagerInterface,
+	emailService *EmailService,
+	cfg config.InvitationConfig,
+) *InvitationService {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		txManager:      txManager,
+		emailService:   emailService,
+		cfg:            cfg,
+	}
+}
+
+// CreateInvitation mails a signed link for the role to the email address.
+// Earlier open invitations for the address are revoked.
+func (s *InvitationService) CreateInvitation(ctx context.Context, req request.CreateInvitationRequest, createdBy string) (*response.InvitationResponse, error) {
+	if req.Role != models.RoleRecipient && req.Role != models.RoleSuperadmin {
+		return nil, ErrInvalidInvitationRole
+	}
+	email := strings.TrimSpace(req.Email)
+
+	emailExists, err := s.userRepo.CheckEmailExists(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if emailExists {
+		return nil, ErrEmailAlreadyRegistered
+	}
+
+	role, err := s.roleRepo.GetRoleByName(req.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	invitation := &models.Invitation{
+		Email:     email,
+		RoleID:    role.RoleID,
+		RoleName:  role.RoleName,
+		ExpiresAt: time.Now().Add(time.Duration(s.cfg.TTLHours) * time.Hour),
+		CreatedBy: createdBy,
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.invitationRepo.RevokePendingForEmail(ctx, email, createdBy); err != nil {
+			return fmt.Errorf("failed to revoke earlier invitations: %w", err)
+		}
+		if err := s.invitationRepo.CreateInvitation(ctx, invitation); err != nil {
+			return fmt.Errorf("failed to create invitation: %w", err)
+		}
+
+		token := s.signToken(invitation.ID, invitation.ExpiresAt)
+		_, err := s.emailService.Queue(ctx, email, "invitation", req.Locale, map[string]interface{}{
+			"Link":           s.cfg.URL + "?token=" + url.QueryEscape(token),
+			"Role":           role.RoleName,
+			"InvitedBy":      createdBy,
+			"ExpiresInHours": s.cfg.TTLHours,
+			"Company":        utils.GetCompanyProfile(),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toInvitationResponse(invitation, time.Now()), nil
+}
+
+func (s *InvitationService) ListInvitations(ctx context.Context, limit, offset int) ([]response.InvitationResponse, error) {
+	invitations, err := s.invitationRepo.ListInvitations(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+
+	now := time.Now()
+	result := make([]response.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		result = append(result, *toInvitationResponse(&invitations[i], now))
+	}
+	return result, nil
+}
+
+// RevokeInvitation makes the link of a pending invitation stop working
+func (s *InvitationService) RevokeInvitation(ctx context.Context, id int64, revokedBy string) (*response.InvitationResponse, error) {
+	revoked, err := s.invitationRepo.RevokeInvitation(ctx, id, revokedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+
+	invitation, err := s.invitationRepo.GetInvitation(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+	if !revoked {
+		return nil, ErrInvitationNotPending
+	}
+
+	return toInvitationResponse(invitation, time.Now()), nil
+}
+
+// AcceptInvitation creates the invited account. The email address comes from
+// the invitation, so it counts as verified.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, req request.AcceptInvitationRequest) (*response.RegisterResponse, error) {
+	id, ok := s.verifyToken(req.Token, time.Now())
+	if !ok {
+		return nil, ErrInvalidInvitation
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var createdUser *models.User
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		invitation, err := s.invitationRepo.GetInvitationForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get invitation: %w", err)
+		}
+		if invitation == nil || invitation.Status(time.Now()) != models.InvitationStatusPending {
+			return ErrInvalidInvitation
+		}
+
+		usernameExists, err := s.userRepo.CheckUsernameExists(req.Username)
+		if err != nil {
+			return fmt.Errorf("failed to check username: %w", err)
+		}
+		if usernameExists {
+			return ErrUsernameAlreadyTaken
+		}
+		emailExists, err := s.userRepo.CheckEmailExists(invitation.Email)
+		if err != nil {
+			return fmt.Errorf("failed to check email: %w", err)
+		}
+		if emailExists {
+			return ErrEmailAlreadyRegistered
+		}
+
+		verifiedAt := time.Now()
+		createdUser, err = s.userRepo.CreateUser(ctx, &models.User{
+			Username:        req.Username,
+			Fullname:        req.Fullname,
+			Email:           invitation.Email,
+			Password:        string(hashedPassword),
+			RoleID:          invitation.RoleID,
+			PhoneNumber:     req.PhoneNumber,
+			Address:         req.Address,
+			IsActive:        true,
+			EmailVerifiedAt: &verifiedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.invitationRepo.MarkAccepted(ctx, invitation.ID, createdUser.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.RegisterResponse{
+		UserID:        createdUser.UserID,
+		UserName:      createdUser.Username,
+		Email:         createdUser.Email,
+		Fullname:      createdUser.Fullname,
+		RoleID:        createdUser.RoleID,
+		EmailVerified: createdUser.IsEmailVerified(),
+	}, nil
+}
+
+// signToken returns "<id>.<expiry>.<signature>", with the expiry in unix seconds
+func (s *InvitationService) signToken(id int64, expiresAt time.Time) string {
+	payload := strconv.FormatInt(id, 10) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + utils.SignHMAC(s.cfg.Secret, []byte(invitationTokenPrefix+payload))
+}
+
+// verifyToken returns the invitation ID of a correctly signed, unexpired token
+func (s *InvitationService) verifyToken(token string, now time.Time) (int64, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, false
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !utils.VerifyHMAC(s.cfg.Secret, []byte(invitationTokenPrefix+payload), parts[2]) {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !now.Before(time.Unix(expiresAt, 0)) {
+		return 0, false
+	}
+
+	return id, true
+}
+
+func toInvitationResponse(invitation *models.Invitation, now time.Time) *response.InvitationResponse {
+	return &response.InvitationResponse{
+		ID:             invitation.ID,
+		Email:          invitation.Email,
+		Role:           invitation.RoleName,
+		Status:         invitation.Status(now),
+		ExpiresAt:      invitation.ExpiresAt,
+		AcceptedAt:     invitation.AcceptedAt,
+		AcceptedUserID: invitation.AcceptedUserID,
+		RevokedAt:      invitation.RevokedAt,
+		RevokedBy:      invitation.RevokedBy,
+		CreatedBy:      invitation.CreatedBy,
+		CreatedAt:      invitation.CreatedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/utils"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvitationTokenRoundTrip(t *testing.T) {
+	service := &InvitationService{cfg: config.InvitationConfig{Secret: "invitation-secret"}}
+	now := time.Now()
+
+	token := service.signToken(42, now.Add(time.Hour))
+	id, ok := service.verifyToken(token, now)
+	if !ok || id != 42 {
+		t.Fatalf("verifyToken(signToken(42)) = %d, %v, want 42, true", id, ok)
+	}
+}
+
+func TestInvitationTokenRejected(t *testing.T) {
+	const secret = "invitation-secret"
+	service := &InvitationService{cfg: config.InvitationConfig{Secret: secret}}
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	token := service.signToken(42, expiresAt)
+	parts := strings.Split(token, ".")
+	payload := "42." + strconv.FormatInt(expiresAt.Unix(), 10)
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+	}{
+		{"expired", token, expiresAt.Add(time.Second)},
+		{"at expiry", token, time.Unix(expiresAt.Unix(), 0)},
+		{"other invitation ID", "43." + parts[1] + "." + parts[2], now},
+		{"extended expiry", parts[0] + "." + strconv.FormatInt(expiresAt.Add(24*time.Hour).Unix(), 10) + "." + parts[2], now},
+		{"tampered signature", parts[0] + "." + parts[1] + "." + strings.Repeat("0", len(parts[2])), now},
+		{"signed with another secret", payload + "." + utils.SignHMAC("another-secret", []byte(invitationTokenPrefix+payload)), now},
+		{"signed without the prefix", payload + "." + utils.SignHMAC(secret, []byte(payload)), now},
+		{"signed with another prefix", payload + "." + utils.SignHMAC(secret, []byte("verification:"+payload)), now},
+		{"missing signature", payload, now},
+		{"extra part", token + ".1", now},
+		{"empty", "", now},
+	}
+
+	for _, tt := range tests {
+		if id, ok := service.verifyToken(tt.token, tt.now); ok {
+			t.Errorf("%s: verifyToken(%q) = %d, true, want rejected", tt.name, tt.token, id)
+		}
+	}
+}
+
+func TestInvitationTokenNeedsSecret(t *testing.T) {
+	service := &InvitationService{cfg: config.InvitationConfig{}}
+	now := time.Now()
+
+	if _, ok := service.verifyToken(service.signToken(42, now.Add(time.Hour)), now); ok {
+		t.Fatal("verifyToken accepted a token signed without a secret")
+	}
+}
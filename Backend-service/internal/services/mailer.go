@@ -0,0 +1,258 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailMessage is a single outbound email with a plain text and an optional HTML body
+type EmailMessage struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer is implemented by every email transport
+type Mailer interface {
+	Name() string
+	Send(ctx context.Context, msg EmailMessage) error
+}
+
+const (
+	SMTPMailerName   = "smtp"
+	OutboxMailerName = "outbox"
+)
+
+// smtpTimeout bounds a whole SMTP conversation when the caller sets no deadline
+const smtpTimeout = 30 * time.Second
+
+// NewMailer returns the mailer selected by MAIL_DRIVER. The outbox mailer
+// never delivers anything, so it is refused outside development and an unset
+// driver only falls back to it in development.
+func NewMailer(cfg *config.MailConfig, development bool) (Mailer, error) {
+	switch cfg.Driver {
+	case "":
+		if !development {
+			return nil, fmt.Errorf("MAIL_DRIVER must be set outside development")
+		}
+		return NewOutboxMailer(cfg.From, cfg.OutboxDir), nil
+	case OutboxMailerName:
+		if !development {
+			return nil, fmt.Errorf("the %s mail driver is only available in development", OutboxMailerName)
+		}
+		return NewOutboxMailer(cfg.From, cfg.OutboxDir), nil
+	case SMTPMailerName:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for the smtp mail driver")
+		}
+		return NewSMTPMailer(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Driver)
+	}
+}
+
+// buildMessage renders msg as an RFC 5322 message, using multipart/alternative
+// when an HTML body is present
+func buildMessage(from string, msg EmailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+
+	messageID, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if addr, err := envelopeAddress(from); err == nil {
+		if at := strings.LastIndex(addr, "@"); at >= 0 {
+			domain = addr[at+1:]
+		}
+	}
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", messageID, domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTMLBody == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		buf.WriteString(msg.TextBody)
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.TextBody},
+		{"text/html; charset=utf-8", msg.HTMLBody},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// envelopeAddress extracts the bare address from a header value such as "Name <user@example.org>"
+func envelopeAddress(value string) (string, error) {
+	addr, err := netmail.ParseAddress(value)
+	if err != nil {
+		return "", err
+	}
+	return addr.Address, nil
+}
+
+// SMTPMailer delivers email through an SMTP relay, upgrading to TLS when the
+// server offers STARTTLS
+type SMTPMailer struct {
+	from     string
+	host     string
+	port     int64
+	username string
+	password string
+}
+
+func NewSMTPMailer(cfg *config.MailConfig) *SMTPMailer {
+	return &SMTPMailer{
+		from:     cfg.From,
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+	}
+}
+
+func (m *SMTPMailer) Name() string {
+	return SMTPMailerName
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg EmailMessage) error {
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	from, err := envelopeAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	to, err := envelopeAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	// net/smtp ignores ctx, so a stalled server is only cut off by the deadline
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.host, strconv.FormatInt(m.port, 10)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if err := netConn.SetDeadline(deadline); err != nil {
+		netConn.Close()
+		return fmt.Errorf("failed to set SMTP deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(netConn, m.host)
+	if err != nil {
+		netConn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("SMTP RCPT TO failed: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// OutboxMailer is the development stand-in for SMTP. Every message is written
+// to the outbox directory as an .eml file that any mail client can open.
+type OutboxMailer struct {
+	from string
+	dir  string
+}
+
+func NewOutboxMailer(from, dir string) *OutboxMailer {
+	return &OutboxMailer{from: from, dir: dir}
+}
+
+func (m *OutboxMailer) Name() string {
+	return OutboxMailerName
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, msg EmailMessage) error {
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	suffix, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(m.dir, fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), suffix[:8]))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	log.Printf("Email %q to %s written to %s", msg.Subject, msg.To, path)
+	return nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"net"
+	"share-the-meal/internal/config"
+	"testing"
+	"time"
+)
+
+func TestNewMailerOutsideDevelopment(t *testing.T) {
+	tests := []struct {
+		driver      string
+		development bool
+		wantErr     bool
+	}{
+		{"", true, false},
+		{OutboxMailerName, true, false},
+		{"", false, true},
+		{OutboxMailerName, false, true},
+		{SMTPMailerName, false, false},
+		{"carrier-pigeon", true, true},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.MailConfig{Driver: tt.driver, SMTPHost: "smtp.example.org", SMTPPort: 587}
+		_, err := NewMailer(cfg, tt.development)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewMailer(%q, development=%v) error = %v, wantErr %v", tt.driver, tt.development, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSMTPMailerGivesUpOnStalledServer(t *testing.T) {
+	// The server accepts connections but never sends its greeting
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := NewSMTPMailer(&config.MailConfig{
+		From:     "Share The Meal <no-reply@example.org>",
+		SMTPHost: addr.IP.String(),
+		SMTPPort: int64(addr.Port),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- mailer.Send(ctx, EmailMessage{To: "donor@example.org", Subject: "Hello", TextBody: "Hello"})
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("Send() to a stalled server succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send() to a stalled server did not return")
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"share-the-meal/internal/config"
+	"share-the-meal/internal/models"
+	"sync"
+)
+
+type PaymentChargeRequest struct {
+	DonationID    int64
+	UserID        int64
+	CampaignID    int64
+	Amount        models.Money
+	Currency      string
+	PaymentMethod string
+	Description   string
+
+	// IdempotencyKey makes the provider return the earlier charge instead of
+	// charging again when a request with the same key is repeated
+	IdempotencyKey string
+}
+
+type PaymentCharge struct {
+	TransactionID string
+	Status        string
+	CheckoutURL   string
+}
+
+// PaymentNotification is a provider webhook payload translated to our terms
+type PaymentNotification struct {
+	EventID       string
+	TransactionID string
+	Status        string
+}
+
+// PaymentGateway is implemented by every payment provider. Statuses returned
+// by a gateway use the models.PaymentStatus* values.
+type PaymentGateway interface {
+	Name() string
+	CreateCharge(ctx context.Context, req PaymentChargeRequest) (*PaymentCharge, error)
+	GetChargeStatus(ctx context.Context, transactionID string) (string, error)
+	Refund(ctx context.Context, transactionID string, amount models.Money, idempotencyKey string) error
+	ParseNotification(payload []byte) (*PaymentNotification, error)
+}
+
+// NewPaymentGateway returns the gateway selected by PAYMENT_PROVIDER. The fake
+// provider never moves money, so it is refused outside development and an
+// unset provider only falls back to it in development.
+func NewPaymentGateway(cfg *config.PaymentConfig, development bool) (PaymentGateway, error) {
+	switch cfg.Provider {
+	case "":
+		if !development {
+			return nil, fmt.Errorf("PAYMENT_PROVIDER must be set outside development")
+		}
+		return NewFakePaymentGateway(), nil
+	case FakeProviderName:
+		if !development {
+			return nil, fmt.Errorf("the %s payment provider is only available in development", FakeProviderName)
+		}
+		return NewFakePaymentGateway(), nil
+	default:
+		return nil, fmt.Errorf("unsupported payment provider: %s", cfg.Provider)
+	}
+}
+
+const (
+	FakeProviderName = "fake"
+
+	// Payment methods understood by the fake provider. Any other method is paid immediately.
+	FakeMethodPending = "fake_pending"
+	FakeMethodFailed  = "fake_failed"
+)
+
+type fakeCharge struct {
+	status     string
+	amount     models.Money
+	refunded   models.Money
+	refundKeys map[string]bool
+}
+
+// FakePaymentGateway is a deterministic in-process provider for development.
+// Charges settle according to the payment method and never leave the process.
+type FakePaymentGateway struct {
+	mu      sync.Mutex
+	charges map[string]*fakeCharge
+	keys    map[string]string
+}
+
+func NewFakePaymentGateway() *FakePaymentGateway {
+	return &FakePaymentGateway{
+		charges: make(map[string]*fakeCharge),
+		keys:    make(map[string]string),
+	}
+}
+
+func (g *FakePaymentGateway) Name() string {
+	return FakeProviderName
+}
+
+func (g *FakePaymentGateway) CreateCharge(ctx context.Context, req PaymentChargeRequest) (*PaymentCharge, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid charge amount: %s", req.Amount)
+	}
+
+	status := models.PaymentStatusPaid
+	switch req.PaymentMethod {
+	case FakeMethodPending:
+		status = models.PaymentStatusPending
+	case FakeMethodFailed:
+		status = models.PaymentStatusFailed
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if transactionID, ok := g.keys[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return &PaymentCharge{
+			TransactionID: transactionID,
+			Status:        g.charges[transactionID].status,
+			CheckoutURL:   "https://payments.fake.local/checkout/" + transactionID,
+		}, nil
+	}
+
+	transactionID := fmt.Sprintf("fake-%d", req.DonationID)
+	g.charges[transactionID] = &fakeCharge{status: status, amount: req.Amount}
+	if req.IdempotencyKey != "" {
+		g.keys[req.IdempotencyKey] = transactionID
+	}
+
+	return &PaymentCharge{
+		TransactionID: transactionID,
+		Status:        status,
+		CheckoutURL:   "https://payments.fake.local/checkout/" + transactionID,
+	}, nil
+}
+
+func (g *FakePaymentGateway) GetChargeStatus(ctx context.Context, transactionID string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[transactionID]
+	if !ok {
+		return "", fmt.Errorf("charge %s not found", transactionID)
+	}
+	return charge.status, nil
+}
+
+// Refund ignores a repeated idempotency key, like real providers do
+func (g *FakePaymentGateway) Refund(ctx context.Context, transactionID string, amount models.Money, idempotencyKey string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[transactionID]
+	if !ok {
+		return fmt.Errorf("charge %s not found", transactionID)
+	}
+	if idempotencyKey != "" && charge.refundKeys[idempotencyKey] {
+		return nil
+	}
+	if charge.status != models.PaymentStatusPaid {
+		return fmt.Errorf("charge %s is %s and cannot be refunded", transactionID, charge.status)
+	}
+	if charge.refunded+amount > charge.amount {
+		return fmt.Errorf("refund of %s exceeds remaining charge amount", amount)
+	}
+
+	charge.refunded += amount
+	if idempotencyKey != "" {
+		if charge.refundKeys == nil {
+			charge.refundKeys = make(map[string]bool)
+		}
+		charge.refundKeys[idempotencyKey] = true
+	}
+	return nil
+}
+
+// ParseNotification reads the fake provider's webhook body:
+// {"event_id": "...", "transaction_id": "...", "status": "paid"}
+func (g *FakePaymentGateway) ParseNotification(payload []byte) (*PaymentNotification, error) {
+	var body struct {
+		EventID       string `json:"event_id"`
+		TransactionID string `json:"transaction_id"`
+		Status        string `json:"status"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid notification payload: %w", err)
+	}
+	if body.EventID == "" || body.TransactionID == "" || body.Status == "" {
+		return nil, fmt.Errorf("notification is missing event_id, transaction_id or status")
+	}
+
+	return &PaymentNotification{
+		EventID:       body.EventID,
+		TransactionID: body.TransactionID,
+		Status:        body.Status,
+	}, nil
+}
+
+// SetChargeStatus simulates the provider settling a pending charge
+func (g *FakePaymentGateway) SetChargeStatus(transactionID, status string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if charge, ok := g.charges[transactionID]; ok {
+		charge.status = status
+	}
+}
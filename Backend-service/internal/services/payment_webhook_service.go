@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+)
+
+var (
+	ErrUnknownPaymentProvider  = errors.New("unknown payment provider")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrInvalidWebhookPayload   = errors.New("invalid webhook payload")
+)
+
+type PaymentWebhookService struct {
+	eventRepo       repository.PaymentWebhookEventRepositoryInterface
+	donationService *DonationService
+	txManager       repository.TxManagerInterface
+	gateway         PaymentGateway
+	webhookSecret   string
+}
+
+func NewPaymentWebhookService(
+	eventRepo repository.PaymentWebhookEventRepositoryInterface,
+	donationService *DonationService,
+	txManager repository.TxManagerInterface,
+	gateway PaymentGateway,
+	webhookSecret string,
+) *PaymentWebhookService {
+	return &PaymentWebhookService{
+		eventRepo:       eventRepo,
+		donationService: donationService,
+		txManager:       txManager,
+		gateway:         gateway,
+		webhookSecret:   webhookSecret,
+	}
+}
+
+// HandleWebhook verifies and stores a provider notification, then applies
+// its payment status. Retried deliveries of an already processed event are
+// acknowledged without touching the donation again.
+func (s *PaymentWebhookService) HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error {
+	if provider != s.gateway.Name() {
+		return ErrUnknownPaymentProvider
+	}
+	if !utils.VerifyHMAC(s.webhookSecret, payload, signature) {
+		return ErrInvalidWebhookSignature
+	}
+	if !json.Valid(payload) {
+		return ErrInvalidWebhookPayload
+	}
+
+	notification, err := s.gateway.ParseNotification(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+
+	event := &models.PaymentWebhookEvent{
+		Provider:      provider,
+		EventID:       notification.EventID,
+		TransactionID: notification.TransactionID,
+		Status:        notification.Status,
+		Payload:       payload,
+		Signature:     signature,
+	}
+	if err := s.eventRepo.SaveEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to save webhook event: %w", err)
+	}
+	if event.Processed {
+		return nil
+	}
+
+	var donation *models.Donation
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		locked, err := s.eventRepo.LockEvent(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+		// A concurrent delivery got here first
+		if locked.Processed {
+			return nil
+		}
+
+		donation, err = s.applyNotification(ctx, notification)
+		if err != nil {
+			return err
+		}
+
+		return s.eventRepo.MarkProcessed(ctx, event.ID)
+	})
+	if err != nil {
+		if markErr := s.eventRepo.MarkFailed(ctx, event.ID, err.Error()); markErr != nil {
+			log.Printf("Failed to record webhook event %d error: %v", event.ID, markErr)
+		}
+		return err
+	}
+
+	if donation != nil && donation.PaymentStatus == models.PaymentStatusPaid {
+		s.donationService.notifyDonationPaid(donation)
+	}
+
+	return nil
+}
+
+// applyNotification returns nil when the donation already has the notified status
+func (s *PaymentWebhookService) applyNotification(ctx context.Context, notification *PaymentNotification) (*models.Donation, error) {
+	donation, err := s.donationService.donationRepo.GetDonationByTransactionID(ctx, notification.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donation: %w", err)
+	}
+	if donation == nil {
+		return nil, fmt.Errorf("%w: transaction %s", ErrDonationNotFound, notification.TransactionID)
+	}
+	if donation.PaymentStatus == notification.Status {
+		return nil, nil
+	}
+
+	return s.donationService.transitionPaymentStatus(ctx, donation.ID, notification.Status)
+}
+
+func (s *PaymentWebhookService) ListEvents(ctx context.Context, limit, offset int) ([]models.PaymentWebhookEvent, error) {
+	return s.eventRepo.ListEvents(ctx, limit, offset)
+}
+
+func (s *PaymentWebhookService) GetEvent(ctx context.Context, id int64) (*models.PaymentWebhookEvent, error) {
+	return s.eventRepo.GetEventByID(ctx, id)
+}
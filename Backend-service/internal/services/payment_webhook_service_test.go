@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+	"sync"
+	"testing"
+)
+
+// memoryWebhookEventRepo stores events in memory, unique per provider and event ID
+type memoryWebhookEventRepo struct {
+	repository.PaymentWebhookEventRepositoryInterface
+	mu     sync.Mutex
+	events map[string]*models.PaymentWebhookEvent
+	byID   map[int64]*models.PaymentWebhookEvent
+}
+
+func newMemoryWebhookEventRepo() *memoryWebhookEventRepo {
+	return &memoryWebhookEventRepo{
+		events: make(map[string]*models.PaymentWebhookEvent),
+		byID:   make(map[int64]*models.PaymentWebhookEvent),
+	}
+}
+
+func (r *memoryWebhookEventRepo) SaveEvent(ctx context.Context, event *models.PaymentWebhookEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := event.Provider + "/" + event.EventID
+	if stored, ok := r.events[key]; ok {
+		event.ID = stored.ID
+		event.Processed = stored.Processed
+		return nil
+	}
+	stored := *event
+	stored.ID = int64(len(r.events) + 1)
+	r.events[key] = &stored
+	r.byID[stored.ID] = &stored
+	event.ID = stored.ID
+	return nil
+}
+
+func (r *memoryWebhookEventRepo) LockEvent(ctx context.Context, id int64) (*models.PaymentWebhookEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *r.byID[id]
+	return &stored, nil
+}
+
+func (r *memoryWebhookEventRepo) MarkProcessed(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[id].Processed = true
+	return nil
+}
+
+func (r *memoryWebhookEventRepo) MarkFailed(ctx context.Context, id int64, processingError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[id].ProcessingError = processingError
+	return nil
+}
+
+// memoryDonationRepo holds a single donation
+type memoryDonationRepo struct {
+	repository.DonationRepositoryInterface
+	donation models.Donation
+	updates  int
+}
+
+func (r *memoryDonationRepo) GetDonationByTransactionID(ctx context.Context, transactionID string) (*models.Donation, error) {
+	if transactionID != r.donation.TransactionID {
+		return nil, nil
+	}
+	donation := r.donation
+	return &donation, nil
+}
+
+func (r *memoryDonationRepo) LockDonation(ctx context.Context, id int64) (*models.Donation, error) {
+	if id != r.donation.ID {
+		return nil, nil
+	}
+	donation := r.donation
+	return &donation, nil
+}
+
+func (r *memoryDonationRepo) UpdatePaymentStatus(ctx context.Context, id int64, fromStatus, toStatus string) error {
+	if r.donation.PaymentStatus != fromStatus {
+		return fmt.Errorf("donation %d is %s, not %s", id, r.donation.PaymentStatus, fromStatus)
+	}
+	r.donation.PaymentStatus = toStatus
+	r.updates++
+	return nil
+}
+
+// memoryCampaignRepo records the amounts added to campaign totals
+type memoryCampaignRepo struct {
+	repository.CampaignRepositoryInterface
+	current models.Money
+}
+
+func (r *memoryCampaignRepo) IncrementCurrentAmount(ctx context.Context, id int64, amount models.Money) error {
+	r.current += amount
+	return nil
+}
+
+// serialTxManager runs fn directly, one call at a time
+type serialTxManager struct {
+	mu sync.Mutex
+}
+
+func (m *serialTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn(ctx)
+}
+
+func TestHandleWebhookIgnoresDuplicateEvents(t *testing.T) {
+	const secret = "webhook-secret"
+	ctx := context.Background()
+
+	gateway := NewFakePaymentGateway()
+	donations := &memoryDonationRepo{donation: models.Donation{
+		ID:            1,
+		CampaignID:    1,
+		Amount:        5000,
+		PaymentStatus: models.PaymentStatusPending,
+		TransactionID: "fake-1",
+	}}
+	campaigns := &memoryCampaignRepo{}
+	events := newMemoryWebhookEventRepo()
+	txManager := &serialTxManager{}
+	donationService := NewDonationService(donations, nil, campaigns, nil, txManager, gateway, nil, nil)
+	service := NewPaymentWebhookService(events, donationService, txManager, gateway, secret)
+
+	payload := []byte(`{"event_id":"evt-1","transaction_id":"fake-1","status":"paid"}`)
+	signature := utils.SignHMAC(secret, payload)
+
+	for i := 0; i < 3; i++ {
+		if err := service.HandleWebhook(ctx, FakeProviderName, payload, signature); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+
+	if donations.donation.PaymentStatus != models.PaymentStatusPaid {
+		t.Errorf("payment status = %s, want %s", donations.donation.PaymentStatus, models.PaymentStatusPaid)
+	}
+	if donations.updates != 1 {
+		t.Errorf("payment status updated %d times, want 1", donations.updates)
+	}
+	if campaigns.current != 5000 {
+		t.Errorf("campaign total = %s, want 50.00", campaigns.current)
+	}
+	if len(events.events) != 1 {
+		t.Errorf("stored %d events, want 1", len(events.events))
+	}
+}
+
+func TestHandleWebhookSkipsProcessedEvent(t *testing.T) {
+	const secret = "webhook-secret"
+	ctx := context.Background()
+
+	gateway := NewFakePaymentGateway()
+	donations := &memoryDonationRepo{donation: models.Donation{
+		ID:            1,
+		CampaignID:    1,
+		Amount:        5000,
+		PaymentStatus: models.PaymentStatusPending,
+		TransactionID: "fake-1",
+	}}
+	campaigns := &memoryCampaignRepo{}
+	events := newMemoryWebhookEventRepo()
+	txManager := &serialTxManager{}
+	donationService := NewDonationService(donations, nil, campaigns, nil, txManager, gateway, nil, nil)
+	service := NewPaymentWebhookService(events, donationService, txManager, gateway, secret)
+
+	// An earlier delivery of the event has been processed already
+	processed := &models.PaymentWebhookEvent{Provider: FakeProviderName, EventID: "evt-1"}
+	if err := events.SaveEvent(ctx, processed); err != nil {
+		t.Fatalf("failed to save event: %v", err)
+	}
+	if err := events.MarkProcessed(ctx, processed.ID); err != nil {
+		t.Fatalf("failed to mark event processed: %v", err)
+	}
+
+	payload := []byte(`{"event_id":"evt-1","transaction_id":"fake-1","status":"paid"}`)
+	if err := service.HandleWebhook(ctx, FakeProviderName, payload, utils.SignHMAC(secret, payload)); err != nil {
+		t.Fatalf("HandleWebhook() error = %v", err)
+	}
+
+	if donations.updates != 0 || donations.donation.PaymentStatus != models.PaymentStatusPending {
+		t.Errorf("processed event was applied again: status %s after %d updates", donations.donation.PaymentStatus, donations.updates)
+	}
+	if campaigns.current != 0 {
+		t.Errorf("campaign total = %s, want 0.00", campaigns.current)
+	}
+}
+
+func TestHandleWebhookRejectsBadSignature(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakePaymentGateway()
+	events := newMemoryWebhookEventRepo()
+	service := NewPaymentWebhookService(events, nil, &serialTxManager{}, gateway, "webhook-secret")
+
+	payload := []byte(`{"event_id":"evt-1","transaction_id":"fake-1","status":"paid"}`)
+	err := service.HandleWebhook(ctx, FakeProviderName, payload, utils.SignHMAC("another-secret", payload))
+	if !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Fatalf("HandleWebhook() error = %v, want %v", err, ErrInvalidWebhookSignature)
+	}
+	if len(events.events) != 0 {
+		t.Errorf("stored %d events for a rejected delivery, want 0", len(events.events))
+	}
+}
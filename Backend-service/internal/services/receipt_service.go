@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+	"text/template"
+)
+
+// TemplateDir holds the PDF templates, next to the HTML templates loaded by the router
+const TemplateDir = "templates"
+
+const receiptPDFTemplate = "donation_receipt_pdf.tmpl"
+
+var ErrReceiptUnavailable = errors.New("receipts are only available for paid donations")
+
+type ReceiptService struct {
+	receiptRepo  repository.DonationReceiptRepositoryInterface
+	donationRepo repository.DonationRepositoryInterface
+	refundRepo   repository.DonationRefundRepositoryInterface
+	campaignRepo repository.CampaignRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	txManager    repository.TxManagerInterface
+}
+
+func NewReceiptService(
+	receiptRepo repository.DonationReceiptRepositoryInterface,
+	donationRepo repository.DonationRepositoryInterface,
+	refundRepo repository.DonationRefundRepositoryInterface,
+	campaignRepo repository.CampaignRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	txManager repository.TxManagerInterface,
+) *ReceiptService {
+	return &ReceiptService{
+		receiptRepo:  receiptRepo,
+		donationRepo: donationRepo,
+		refundRepo:   refundRepo,
+		campaignRepo: campaignRepo,
+		userRepo:     userRepo,
+		txManager:    txManager,
+	}
+}
+
+// GetDonationReceipt returns the receipt of a paid donation owned by userID.
+// The receipt number is assigned the first time the receipt is requested and
+// never changes afterwards.
+func (s *ReceiptService) GetDonationReceipt(ctx context.Context, donationID, userID int64) (*response.DonationReceiptResponse, error) {
+	var donation *models.Donation
+	var receipt *models.DonationReceipt
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		// Locking the donation keeps two requests from numbering the same receipt
+		donation, err = s.donationRepo.LockDonation(ctx, donationID)
+		if err != nil {
+			return fmt.Errorf("failed to get donation: %w", err)
+		}
+		if donation == nil || donation.UserID != userID {
+			return ErrDonationNotFound
+		}
+		if !donation.IsSettled() {
+			return ErrReceiptUnavailable
+		}
+
+		receipt, err = s.receiptRepo.GetReceiptByDonationID(ctx, donationID)
+		if err != nil || receipt != nil {
+			return err
+		}
+
+		year := donation.CreatedAt.Year()
+		sequence, err := s.receiptRepo.NextSequenceNumber(ctx, year)
+		if err != nil {
+			return fmt.Errorf("failed to number receipt: %w", err)
+		}
+
+		receipt = &models.DonationReceipt{
+			DonationID:     donationID,
+			ReceiptYear:    year,
+			SequenceNumber: sequence,
+			ReceiptNumber:  models.FormatReceiptNumber(year, sequence),
+		}
+		return s.receiptRepo.CreateReceipt(ctx, receipt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, donation.CampaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+	user, err := s.userRepo.GetUserByID(ctx, donation.UserID)
+	if err != nil {
+		return nil, err
+	}
+	_, refunded, err := s.refundRepo.GetRefundedTotals(ctx, donationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunded totals: %w", err)
+	}
+
+	donorName := user.Fullname
+	if donorName == "" {
+		donorName = user.Username
+	}
+
+	return &response.DonationReceiptResponse{
+		ReceiptNumber:  receipt.ReceiptNumber,
+		IssuedAt:       receipt.IssuedAt,
+		DonationID:     donation.ID,
+		DonationDate:   donation.CreatedAt,
+		DonorName:      donorName,
+		DonorEmail:     user.Email,
+		CampaignTitle:  campaign.Title,
+		Amount:         donation.OriginalAmount,
+		RefundedAmount: refunded,
+		NetAmount:      donation.OriginalAmount - refunded,
+		Currency:       donation.Currency,
+		PaymentMethod:  donation.PaymentMethod,
+		TransactionID:  donation.TransactionID,
+		Company:        utils.GetCompanyProfile(),
+	}, nil
+}
+
+// RenderReceiptPDF renders the receipt through the PDF text template
+func (s *ReceiptService) RenderReceiptPDF(receipt *response.DonationReceiptResponse) ([]byte, error) {
+	return renderPDFTemplate(receiptPDFTemplate, receipt)
+}
+
+// renderPDFTemplate executes a text template from TemplateDir and lays the output out as a PDF
+func renderPDFTemplate(name string, data interface{}) ([]byte, error) {
+	tmpl, err := template.ParseFiles(filepath.Join(TemplateDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+
+	return utils.RenderTextPDF(buf.String()), nil
+}
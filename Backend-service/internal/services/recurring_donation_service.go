@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"share-the-meal/internal/dto/request"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+	"time"
+)
+
+// recurringBatchSize caps how many schedules one scheduler run charges
+const recurringBatchSize = 100
+
+var (
+	ErrRecurringDonationNotFound = errors.New("recurring donation not found")
+	ErrInvalidRecurringStatus    = errors.New("invalid recurring donation status change")
+)
+
+type RecurringDonationService struct {
+	recurringRepo   repository.RecurringDonationRepositoryInterface
+	campaignRepo    repository.CampaignRepositoryInterface
+	donationService *DonationService
+	txManager       repository.TxManagerInterface
+}
+
+func NewRecurringDonationService(
+	recurringRepo repository.RecurringDonationRepositoryInterface,
+	campaignRepo repository.CampaignRepositoryInterface,
+	donationService *DonationService,
+	txManager repository.TxManagerInterface,
+) *RecurringDonationService {
+	return &RecurringDonationService{
+		recurringRepo:   recurringRepo,
+		campaignRepo:    campaignRepo,
+		donationService: donationService,
+		txManager:       txManager,
+	}
+}
+
+func (s *RecurringDonationService) CreateRecurringDonation(ctx context.Context, req request.RecurringDonationRequest, userID int64, username string) (*response.RecurringDonationResponse, error) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, req.CampaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+	if !campaign.IsPublished() {
+		return nil, fmt.Errorf("%w: campaign is %s", ErrCampaignNotActive, campaign.Status)
+	}
+
+	currency := utils.NormalizeCurrency(req.Currency)
+	if currency == "" {
+		currency = campaign.Currency
+	}
+	if !utils.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+
+	interval := req.Interval
+	if interval == "" {
+		interval = models.RecurringIntervalMonthly
+	}
+
+	// The first donation is generated by the scheduler once the start date is reached
+	start := time.Now()
+	if req.StartDate != nil && req.StartDate.After(start) {
+		start = *req.StartDate
+	}
+
+	recurring := &models.RecurringDonation{
+		UserID:         userID,
+		CampaignID:     req.CampaignID,
+		Amount:         req.Amount,
+		Currency:       currency,
+		IsAnonymous:    req.IsAnonymous,
+		PaymentMethod:  req.PaymentMethod,
+		Interval:       interval,
+		AnchorDay:      start.Day(),
+		NextChargeDate: start,
+		Status:         models.RecurringStatusActive,
+		CreatedBy:      username,
+	}
+
+	if err := s.recurringRepo.CreateRecurringDonation(ctx, recurring); err != nil {
+		return nil, err
+	}
+
+	return newRecurringDonationResponse(recurring), nil
+}
+
+func (s *RecurringDonationService) GetUserRecurringDonations(ctx context.Context, userID int64) ([]*response.RecurringDonationResponse, error) {
+	recurring, err := s.recurringRepo.GetUserRecurringDonations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*response.RecurringDonationResponse
+	for i := range recurring {
+		responses = append(responses, newRecurringDonationResponse(&recurring[i]))
+	}
+	return responses, nil
+}
+
+func (s *RecurringDonationService) PauseRecurringDonation(ctx context.Context, id, userID int64, username string) (*response.RecurringDonationResponse, error) {
+	return s.changeStatus(ctx, id, userID, username, models.RecurringStatusActive, models.RecurringStatusPaused)
+}
+
+func (s *RecurringDonationService) ResumeRecurringDonation(ctx context.Context, id, userID int64, username string) (*response.RecurringDonationResponse, error) {
+	return s.changeStatus(ctx, id, userID, username, models.RecurringStatusPaused, models.RecurringStatusActive)
+}
+
+func (s *RecurringDonationService) CancelRecurringDonation(ctx context.Context, id, userID int64, username string) (*response.RecurringDonationResponse, error) {
+	return s.changeStatus(ctx, id, userID, username, "", models.RecurringStatusCancelled)
+}
+
+// changeStatus moves a schedule owned by userID to status. An empty from
+// accepts any status that is not cancelled.
+func (s *RecurringDonationService) changeStatus(ctx context.Context, id, userID int64, username, from, to string) (*response.RecurringDonationResponse, error) {
+	recurring, err := s.recurringRepo.GetRecurringDonationByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring donation: %w", err)
+	}
+	if recurring == nil || recurring.UserID != userID {
+		return nil, ErrRecurringDonationNotFound
+	}
+
+	if recurring.Status == models.RecurringStatusCancelled || (from != "" && recurring.Status != from) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidRecurringStatus, recurring.Status, to)
+	}
+
+	// Periods missed while paused are skipped rather than charged on resume
+	now := time.Now()
+	if to == models.RecurringStatusActive {
+		recurring.NextChargeDate = nextChargeAfter(recurring, recurring.NextChargeDate, now)
+	}
+
+	if err := s.recurringRepo.UpdateStatus(ctx, id, to, recurring.NextChargeDate, username); err != nil {
+		return nil, err
+	}
+	recurring.Status = to
+
+	return newRecurringDonationResponse(recurring), nil
+}
+
+// ProcessDueDonations generates a donation for every active schedule whose
+// next charge date has passed and returns how many were generated. Each
+// schedule is charged and advanced in its own transaction, so a restart never
+// charges the same period twice. A schedule that fails is retried on the
+// next run.
+func (s *RecurringDonationService) ProcessDueDonations(ctx context.Context, now time.Time) (int, error) {
+	var failed []int64
+	processed, skipped := 0, 0
+
+	for processed+skipped+len(failed) < recurringBatchSize {
+		var recurring *models.RecurringDonation
+		charged := false
+		err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			due, err := s.recurringRepo.LockDueRecurringDonations(ctx, now, failed, 1)
+			if err != nil {
+				return fmt.Errorf("failed to get due recurring donations: %w", err)
+			}
+			if len(due) == 0 {
+				return nil
+			}
+			recurring = &due[0]
+
+			charged, err = s.chargeRecurringDonation(ctx, recurring, now)
+			return err
+		})
+
+		if recurring == nil {
+			return processed, err
+		}
+		if err != nil {
+			log.Printf("Failed to charge recurring donation %d: %v", recurring.ID, err)
+			failed = append(failed, recurring.ID)
+			continue
+		}
+		if charged {
+			processed++
+		} else {
+			skipped++
+		}
+	}
+
+	return processed, nil
+}
+
+// chargeRecurringDonation must run inside a transaction. It reports whether
+// a donation was generated: schedules of campaigns that are not open yet skip
+// the period, and schedules of campaigns that have ended are cancelled.
+func (s *RecurringDonationService) chargeRecurringDonation(ctx context.Context, recurring *models.RecurringDonation, now time.Time) (bool, error) {
+	// Only one donation is generated however many periods were missed
+	next := nextChargeAfter(recurring, recurring.NextChargeDate, now)
+
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, recurring.CampaignID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get campaign: %w", err)
+	}
+	switch {
+	case campaign.IsClosed():
+		return false, s.recurringRepo.UpdateStatus(ctx, recurring.ID, models.RecurringStatusCancelled, recurring.NextChargeDate, "system")
+	case !campaign.AcceptsDonations(now):
+		return false, s.recurringRepo.UpdateStatus(ctx, recurring.ID, recurring.Status, next, "system")
+	}
+
+	// The charge cannot be rolled back with the transaction. The key names the
+	// period, so if the commit fails the next run gets the same charge back
+	// from the provider instead of charging the donor again.
+	recurringID := recurring.ID
+	_, err = s.donationService.CreateDonation(ctx, request.DonationRequest{
+		CampaignID:          recurring.CampaignID,
+		Amount:              recurring.Amount,
+		Currency:            recurring.Currency,
+		IsAnonymous:         recurring.IsAnonymous,
+		PaymentMethod:       recurring.PaymentMethod,
+		RecurringDonationID: &recurringID,
+		IdempotencyKey:      recurringChargeKey(recurring),
+	}, recurring.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, s.recurringRepo.AdvanceNextChargeDate(ctx, recurring.ID, now, next)
+}
+
+// recurringChargeKey identifies the period a schedule is being charged for
+func recurringChargeKey(recurring *models.RecurringDonation) string {
+	return fmt.Sprintf("recurring-%d-%d", recurring.ID, recurring.NextChargeDate.Unix())
+}
+
+// nextChargeAfter returns the first charge date on the schedule after now
+func nextChargeAfter(recurring *models.RecurringDonation, from, now time.Time) time.Time {
+	next := from
+	for !next.After(now) {
+		next = recurring.NextCharge(next)
+	}
+	return next
+}
+
+func newRecurringDonationResponse(r *models.RecurringDonation) *response.RecurringDonationResponse {
+	return &response.RecurringDonationResponse{
+		ID:             r.ID,
+		CampaignID:     r.CampaignID,
+		Amount:         r.Amount,
+		Currency:       r.Currency,
+		IsAnonymous:    r.IsAnonymous,
+		PaymentMethod:  r.PaymentMethod,
+		Interval:       r.Interval,
+		NextChargeDate: r.NextChargeDate,
+		LastChargedAt:  r.LastChargedAt,
+		Status:         r.Status,
+		CreatedAt:      r.CreatedAt,
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/testdb"
+	"testing"
+	"time"
+)
+
+// commitFailingTxManager rolls back the next top-level transaction after fn
+// succeeded, the way a failed commit would
+type commitFailingTxManager struct {
+	repository.TxManagerInterface
+	failNext bool
+}
+
+var errCommitFailed = errors.New("commit failed")
+
+func (m *commitFailingTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.TxManagerInterface.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		if m.failNext {
+			m.failNext = false
+			return errCommitFailed
+		}
+		return nil
+	})
+}
+
+func TestRecurringChargeIsNotRepeatedAfterFailedCommit(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	gateway := NewFakePaymentGateway()
+	donor := createTestDonor(t, db, "recurring-donor")
+	campaign := createTestCampaign(t, db)
+
+	recurringRepo := repository.NewRecurringDonationRepository(db, "public")
+	now := time.Now()
+	recurring := &models.RecurringDonation{
+		UserID:         donor.UserID,
+		CampaignID:     campaign.CampaignID,
+		Amount:         2500,
+		Currency:       campaign.Currency,
+		PaymentMethod:  "card",
+		Interval:       models.RecurringIntervalMonthly,
+		AnchorDay:      now.Day(),
+		NextChargeDate: now.Add(-time.Minute),
+		Status:         models.RecurringStatusActive,
+		CreatedBy:      donor.Username,
+	}
+	if err := recurringRepo.CreateRecurringDonation(ctx, recurring); err != nil {
+		t.Fatalf("failed to create recurring donation: %v", err)
+	}
+
+	txManager := &commitFailingTxManager{TxManagerInterface: repository.NewTxManager(db), failNext: true}
+	service := NewRecurringDonationService(
+		recurringRepo,
+		repository.NewCampaignRepository(db, "public"),
+		newTestDonationService(db, gateway),
+		txManager,
+	)
+
+	// The first run charges the donor but its transaction is rolled back
+	if processed, _ := service.ProcessDueDonations(ctx, now); processed != 0 {
+		t.Fatalf("first run processed %d donations, want 0", processed)
+	}
+	processed, err := service.ProcessDueDonations(ctx, now)
+	if err != nil || processed != 1 {
+		t.Fatalf("second run processed %d donations (err %v), want 1", processed, err)
+	}
+
+	if len(gateway.charges) != 1 {
+		t.Errorf("provider holds %d charges, want 1", len(gateway.charges))
+	}
+
+	var count int
+	err = db.QueryRow(ctx, `SELECT COUNT(*) FROM donations WHERE recurring_donation_id = $1`, recurring.ID).Scan(&count)
+	if err != nil {
+		t.Fatalf("failed to count donations: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("recurring donation generated %d donations, want 1", count)
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session has been revoked")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+// SessionClient describes the device a session is started or refreshed from
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionService issues short-lived access tokens together with rotating
+// refresh tokens. Each refresh token can be exchanged exactly once; presenting
+// a used one revokes the whole session, since either the client or an
+// attacker holds a stolen copy.
+type SessionService struct {
+	sessionRepo repository.SessionRepositoryInterface
+	userRepo    repository.UserRepositoryInterface
+	roleRepo    repository.RoleRepositoryInterface
+	txManager   repository.TxManagerInterface
+	jwtUtil     utils.JWTUtilInterface
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+}
+
+func NewSessionService(
+	sessionRepo repository.SessionRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	roleRepo repository.RoleRepositoryInterface,
+	txManager repository.TxManagerInterface,
+	jwtUtil utils.JWTUtilInterface,
+	accessTTL, refreshTTL time.Duration,
+) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		txManager:   txManager,
+		jwtUtil:     jwtUtil,
+		accessTTL:   accessTTL,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+// StartSession signs a user in on a new device
+func (s *SessionService) StartSession(ctx context.Context, user *models.User, roleName string, client SessionClient) (*response.SignInResponse, error) {
+	var tokens *response.SignInResponse
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		session := &models.UserSession{
+			UserID:    user.UserID,
+			UserAgent: client.UserAgent,
+			IPAddress: client.IPAddress,
+			ExpiresAt: time.Now().Add(s.refreshTTL),
+		}
+		if err := s.sessionRepo.CreateSession(ctx, session); err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+
+		var err error
+		tokens, err = s.issueTokens(ctx, user, roleName, session.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string, client SessionClient) (*response.SignInResponse, error) {
+	var tokens *response.SignInResponse
+	reused := false
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		token, err := s.sessionRepo.GetRefreshTokenForUpdate(ctx, utils.HashToken(refreshToken))
+		if err != nil {
+			return fmt.Errorf("failed to get refresh token: %w", err)
+		}
+		if token == nil {
+			return ErrInvalidRefreshToken
+		}
+
+		session, err := s.sessionRepo.GetSession(ctx, token.SessionID)
+		if err != nil {
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+		now := time.Now()
+		if session == nil || !session.IsActive(now) {
+			return ErrInvalidRefreshToken
+		}
+
+		// The revocation has to be committed, so reuse is reported after the transaction
+		if token.IsUsed() {
+			reused = true
+			_, err := s.sessionRepo.RevokeSession(ctx, session.ID, session.UserID, models.SessionRevokedTokenReuse)
+			return err
+		}
+		if !now.Before(token.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		// Deactivated users are not found, which ends their sessions at the next refresh
+		user, err := s.userRepo.GetUserByID(ctx, session.UserID)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		role, err := s.roleRepo.GetRoleByID(user.RoleID)
+		if err != nil {
+			return fmt.Errorf("failed to get role: %v", err)
+		}
+
+		if err := s.sessionRepo.MarkRefreshTokenUsed(ctx, token.ID); err != nil {
+			return fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+		if err := s.sessionRepo.TouchSession(ctx, session.ID, client.UserAgent, client.IPAddress, now.Add(s.refreshTTL)); err != nil {
+			return fmt.Errorf("failed to update session: %w", err)
+		}
+
+		tokens, err = s.issueTokens(ctx, user, role.RoleName, session.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrRefreshTokenReused
+	}
+
+	return tokens, nil
+}
+
+// Logout revokes the session the refresh token belongs to. Unknown tokens are
+// ignored so that signing out never fails on the client.
+func (s *SessionService) Logout(ctx context.Context, refreshToken string) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		token, err := s.sessionRepo.GetRefreshTokenForUpdate(ctx, utils.HashToken(refreshToken))
+		if err != nil {
+			return fmt.Errorf("failed to get refresh token: %w", err)
+		}
+		if token == nil {
+			return nil
+		}
+
+		session, err := s.sessionRepo.GetSession(ctx, token.SessionID)
+		if err != nil {
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+		if session == nil {
+			return nil
+		}
+
+		_, err = s.sessionRepo.RevokeSession(ctx, session.ID, session.UserID, models.SessionRevokedLogout)
+		return err
+	})
+}
+
+// ListSessions returns the active sessions of a user, marking the one the
+// current access token was issued for
+func (s *SessionService) ListSessions(ctx context.Context, userID, currentSessionID int64) ([]response.SessionResponse, error) {
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	result := make([]response.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, response.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return result, nil
+}
+
+// RevokeSession signs one of the user's own devices out. Its access token
+// stays valid until it expires, at most the access token lifetime.
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	revoked, err := s.sessionRepo.RevokeSession(ctx, sessionID, userID, models.SessionRevokedByUser)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeUserSessions signs a user out everywhere
+func (s *SessionService) RevokeUserSessions(ctx context.Context, userID int64, reason string) error {
+	if _, err := s.sessionRepo.RevokeUserSessions(ctx, userID, reason); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+func (s *SessionService) issueTokens(ctx context.Context, user *models.User, roleName string, sessionID int64) (*response.SignInResponse, error) {
+	now := time.Now()
+
+	accessToken, err := s.jwtUtil.GenerateJWT(user.Username, user.UserID, roleName, sessionID, s.accessTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	if err := s.sessionRepo.CreateRefreshToken(ctx, &models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTTL),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &response.SignInResponse{
+		UserID:       user.UserID,
+		UserName:     user.Username,
+		Role:         roleName,
+		Token:        accessToken,
+		ExpiresAt:    now.Add(s.accessTTL),
+		RefreshToken: refreshToken,
+	}, nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"share-the-meal/internal/models"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+	"testing"
+	"time"
+)
+
+// memorySessionRepo keeps sessions and refresh tokens in memory
+type memorySessionRepo struct {
+	sessions map[int64]*models.UserSession
+	tokens   map[string]*models.RefreshToken
+}
+
+func newMemorySessionRepo() *memorySessionRepo {
+	return &memorySessionRepo{
+		sessions: make(map[int64]*models.UserSession),
+		tokens:   make(map[string]*models.RefreshToken),
+	}
+}
+
+func (r *memorySessionRepo) CreateSession(ctx context.Context, session *models.UserSession) error {
+	session.ID = int64(len(r.sessions) + 1)
+	session.CreatedAt = time.Now()
+	session.LastUsedAt = session.CreatedAt
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return nil
+}
+
+func (r *memorySessionRepo) GetSession(ctx context.Context, id int64) (*models.UserSession, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := *session
+	return &stored, nil
+}
+
+func (r *memorySessionRepo) ListActiveSessions(ctx context.Context, userID int64) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.IsActive(time.Now()) {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *memorySessionRepo) TouchSession(ctx context.Context, id int64, userAgent, ipAddress string, expiresAt time.Time) error {
+	session := r.sessions[id]
+	session.UserAgent = userAgent
+	session.IPAddress = ipAddress
+	session.LastUsedAt = time.Now()
+	session.ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *memorySessionRepo) RevokeSession(ctx context.Context, id, userID int64, reason string) (bool, error) {
+	session, ok := r.sessions[id]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.RevokeReason = reason
+	return true, nil
+}
+
+func (r *memorySessionRepo) RevokeUserSessions(ctx context.Context, userID int64, reason string) (int64, error) {
+	var revoked int64
+	for id := range r.sessions {
+		if ok, _ := r.RevokeSession(ctx, id, userID, reason); ok {
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (r *memorySessionRepo) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	token.ID = int64(len(r.tokens) + 1)
+	token.CreatedAt = time.Now()
+	stored := *token
+	r.tokens[token.TokenHash] = &stored
+	return nil
+}
+
+func (r *memorySessionRepo) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	stored := *token
+	return &stored, nil
+}
+
+func (r *memorySessionRepo) MarkRefreshTokenUsed(ctx context.Context, id int64) error {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			now := time.Now()
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// memoryUserRepo serves a fixed set of users by ID
+type memoryUserRepo struct {
+	repository.UserRepositoryInterface
+	users map[int64]*models.User
+}
+
+func (r *memoryUserRepo) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// memoryRoleRepo knows a single role
+type memoryRoleRepo struct {
+	repository.RoleRepositoryInterface
+	role models.Role
+}
+
+func (r *memoryRoleRepo) GetRoleByID(roleID int64) (*models.Role, error) {
+	return &r.role, nil
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{UserID: 7, Username: "donor", RoleID: 2}
+	sessions := newMemorySessionRepo()
+	service := NewSessionService(
+		sessions,
+		&memoryUserRepo{users: map[int64]*models.User{user.UserID: user}},
+		&memoryRoleRepo{role: models.Role{RoleID: 2, RoleName: models.RoleDonor}},
+		&serialTxManager{},
+		utils.NewJWTUtil("test-secret"),
+		15*time.Minute,
+		24*time.Hour,
+	)
+	client := SessionClient{UserAgent: "test", IPAddress: "127.0.0.1"}
+
+	first, err := service.StartSession(ctx, user, models.RoleDonor, client)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	other, err := service.StartSession(ctx, user, models.RoleDonor, client)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+
+	second, err := service.Refresh(ctx, first.RefreshToken, client)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("Refresh() returned the refresh token it was given")
+	}
+
+	// Presenting the rotated token again revokes the whole session
+	if _, err := service.Refresh(ctx, first.RefreshToken, client); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reusing a refresh token: error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+	session := sessions.sessions[1]
+	if session.RevokedAt == nil || session.RevokeReason != models.SessionRevokedTokenReuse {
+		t.Fatalf("session after reuse = %+v, want it revoked for %s", session, models.SessionRevokedTokenReuse)
+	}
+
+	// The newest token of the family is dead too
+	if _, err := service.Refresh(ctx, second.RefreshToken, client); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("refreshing a revoked session: error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	// Other devices of the same user keep working
+	if _, err := service.Refresh(ctx, other.RefreshToken, client); err != nil {
+		t.Fatalf("refreshing another session: error = %v", err)
+	}
+}
+
+func TestRefreshRejectsUnknownToken(t *testing.T) {
+	service := NewSessionService(newMemorySessionRepo(), nil, nil, &serialTxManager{}, utils.NewJWTUtil("test-secret"), time.Minute, time.Hour)
+
+	if _, err := service.Refresh(context.Background(), "not-a-token", SessionClient{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+}
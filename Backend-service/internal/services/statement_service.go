@@ -0,0 +1,240 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"share-the-meal/internal/dto/response"
+	"share-the-meal/internal/repository"
+	"share-the-meal/internal/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const statementPDFTemplate = "donation_statement_pdf.tmpl"
+
+type StatementService struct {
+	donationRepo repository.DonationRepositoryInterface
+	refundRepo   repository.DonationRefundRepositoryInterface
+	campaignRepo repository.CampaignRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+}
+
+func NewStatementService(
+	donationRepo repository.DonationRepositoryInterface,
+	refundRepo repository.DonationRefundRepositoryInterface,
+	campaignRepo repository.CampaignRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+) *StatementService {
+	return &StatementService{
+		donationRepo: donationRepo,
+		refundRepo:   refundRepo,
+		campaignRepo: campaignRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// statementKey groups donations by campaign and currency, since one campaign
+// can receive donations from the same donor in several currencies
+type statementKey struct {
+	campaignID int64
+	currency   string
+}
+
+// GetDonationStatement aggregates the paid donations of userID made in year
+// by campaign and month. Refunds are subtracted and fully refunded donations
+// are left out.
+func (s *StatementService) GetDonationStatement(ctx context.Context, userID int64, year int) (*response.DonationStatementResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	donations, err := s.donationRepo.GetUserDonations(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donations: %w", err)
+	}
+
+	refunds, err := s.refundRepo.GetUserRefundedTotals(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunds: %w", err)
+	}
+
+	campaigns := make(map[statementKey]*response.StatementCampaignResponse)
+	months := make(map[statementKey]map[time.Month]*response.StatementMonthResponse)
+	totals := make(map[string]*response.StatementTotalResponse)
+
+	for _, d := range donations {
+		if !d.IsSettled() || d.CreatedAt.Year() != year {
+			continue
+		}
+		amount := d.OriginalAmount - refunds[d.ID]
+		if amount <= 0 {
+			continue
+		}
+
+		key := statementKey{campaignID: d.CampaignID, currency: d.Currency}
+		campaign, ok := campaigns[key]
+		if !ok {
+			campaign = &response.StatementCampaignResponse{CampaignID: d.CampaignID, Currency: d.Currency}
+			campaigns[key] = campaign
+			months[key] = make(map[time.Month]*response.StatementMonthResponse)
+		}
+		campaign.Amount += amount
+		campaign.DonationCount++
+
+		month, ok := months[key][d.CreatedAt.Month()]
+		if !ok {
+			month = &response.StatementMonthResponse{Month: int(d.CreatedAt.Month()), MonthName: d.CreatedAt.Month().String()}
+			months[key][d.CreatedAt.Month()] = month
+		}
+		month.Amount += amount
+		month.DonationCount++
+
+		total, ok := totals[d.Currency]
+		if !ok {
+			total = &response.StatementTotalResponse{Currency: d.Currency}
+			totals[d.Currency] = total
+		}
+		total.Amount += amount
+		total.DonationCount++
+	}
+
+	donorName := user.Fullname
+	if donorName == "" {
+		donorName = user.Username
+	}
+
+	statement := &response.DonationStatementResponse{
+		Year:        year,
+		DonorName:   donorName,
+		DonorEmail:  user.Email,
+		GeneratedAt: time.Now(),
+		Totals:      []response.StatementTotalResponse{},
+		Campaigns:   []response.StatementCampaignResponse{},
+		Company:     utils.GetCompanyProfile(),
+	}
+
+	titles := make(map[int64]string)
+	for key, campaign := range campaigns {
+		title, ok := titles[key.campaignID]
+		if !ok {
+			c, err := s.campaignRepo.GetCampaignByID(ctx, key.campaignID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get campaign %d: %w", key.campaignID, err)
+			}
+			title = c.Title
+			titles[key.campaignID] = title
+		}
+		campaign.CampaignTitle = title
+
+		for _, month := range months[key] {
+			campaign.Months = append(campaign.Months, *month)
+		}
+		sort.Slice(campaign.Months, func(i, j int) bool { return campaign.Months[i].Month < campaign.Months[j].Month })
+
+		statement.Campaigns = append(statement.Campaigns, *campaign)
+	}
+	sort.Slice(statement.Campaigns, func(i, j int) bool {
+		a, b := statement.Campaigns[i], statement.Campaigns[j]
+		if a.CampaignTitle != b.CampaignTitle {
+			return a.CampaignTitle < b.CampaignTitle
+		}
+		return a.Currency < b.Currency
+	})
+
+	for _, total := range totals {
+		statement.Totals = append(statement.Totals, *total)
+	}
+	sort.Slice(statement.Totals, func(i, j int) bool { return statement.Totals[i].Currency < statement.Totals[j].Currency })
+
+	return statement, nil
+}
+
+// RenderStatementCSV writes one row per campaign, currency and month
+func (s *StatementService) RenderStatementCSV(statement *response.DonationStatementResponse) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	w.Write([]string{"year", "campaign_id", "campaign_title", "month", "currency", "donation_count", "amount"})
+	for _, campaign := range statement.Campaigns {
+		for _, month := range campaign.Months {
+			w.Write([]string{
+				strconv.Itoa(statement.Year),
+				strconv.FormatInt(campaign.CampaignID, 10),
+				csvText(campaign.CampaignTitle),
+				month.MonthName,
+				campaign.Currency,
+				strconv.Itoa(month.DonationCount),
+				month.Amount.String(),
+			})
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csvText prefixes text that a spreadsheet would read as a formula with a
+// quote, so campaign titles cannot inject formulas into a donor's export
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (s *StatementService) RenderStatementPDF(statement *response.DonationStatementResponse) ([]byte, error) {
+	return renderPDFTemplate(statementPDFTemplate, statement)
+}
+
+// GenerateYearStatements renders the PDF statement of every donor with a paid
+// donation in year and streams them to w as a ZIP archive, one statement at a
+// time. It returns the number of statements written.
+func (s *StatementService) GenerateYearStatements(ctx context.Context, year int, w io.Writer) (int, error) {
+	donorIDs, err := s.donationRepo.GetDonorIDsByYear(ctx, year)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get donors: %w", err)
+	}
+
+	archive := zip.NewWriter(w)
+	generated := 0
+
+	for _, userID := range donorIDs {
+		statement, err := s.GetDonationStatement(ctx, userID, year)
+		if err != nil {
+			return 0, fmt.Errorf("failed to build statement for user %d: %w", userID, err)
+		}
+		// Donors whose donations were all refunded get no statement
+		if len(statement.Campaigns) == 0 {
+			continue
+		}
+
+		pdf, err := s.RenderStatementPDF(statement)
+		if err != nil {
+			return 0, err
+		}
+
+		f, err := archive.Create(fmt.Sprintf("statement-%d-user-%d.pdf", year, userID))
+		if err != nil {
+			return 0, err
+		}
+		if _, err := f.Write(pdf); err != nil {
+			return 0, err
+		}
+		generated++
+	}
+
+	if err := archive.Close(); err != nil {
+		return 0, err
+	}
+	return generated, nil
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"encoding/csv"
+	"share-the-meal/internal/dto/response"
+	"strings"
+	"testing"
+)
+
+func TestRenderStatementCSVEscapesFormulas(t *testing.T) {
+	titles := map[string]string{
+		"Meals for Jakarta":          "Meals for Jakarta",
+		`=HYPERLINK("http://x","y")`: `'=HYPERLINK("http://x","y")`,
+		"+1 meal":                    "'+1 meal",
+		"-5 days left":               "'-5 days left",
+		"@SUM(A1:A2)":                "'@SUM(A1:A2)",
+		"\tTabbed":                   "'\tTabbed",
+		"School = food":              "School = food",
+		"":                           "",
+	}
+
+	service := &StatementService{}
+	for title, want := range titles {
+		statement := &response.DonationStatementResponse{
+			Year: 2025,
+			Campaigns: []response.StatementCampaignResponse{{
+				CampaignID:    1,
+				CampaignTitle: title,
+				Currency:      "USD",
+				Months:        []response.StatementMonthResponse{{Month: 1, MonthName: "January", Amount: 1000, DonationCount: 1}},
+			}},
+		}
+
+		out, err := service.RenderStatementCSV(statement)
+		if err != nil {
+			t.Fatalf("RenderStatementCSV(%q) error = %v", title, err)
+		}
+		rows, err := csv.NewReader(strings.NewReader(string(out))).ReadAll()
+		if err != nil {
+			t.Fatalf("RenderStatementCSV(%q) wrote invalid CSV: %v", title, err)
+		}
+		if got := rows[1][2]; got != want {
+			t.Errorf("campaign_title for %q = %q, want %q", title, got, want)
+		}
+	}
+}
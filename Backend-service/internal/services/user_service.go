@@ -30,6 +30,7 @@ func (s *UserService) GetUserProfile(ctx context.Context, userID int64) (*respon
 		ProfilePicture: user.ProfilePicture,
 		PhoneNumber:    user.PhoneNumber,
 		Address:        user.Address,
+		EmailVerified:  user.IsEmailVerified(),
 	}, nil
 }
 
@@ -68,5 +69,6 @@ func (s *UserService) UpdateProfile(ctx context.Context, userID int64, req reque
 		ProfilePicture: user.ProfilePicture,
 		PhoneNumber:    user.PhoneNumber,
 		Address:        user.Address,
+		EmailVerified:  user.IsEmailVerified(),
 	}, nil
 }
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// defaultBlockedWords is extended with MESSAGE_BLOCKLIST from the environment
+var defaultBlockedWords = []string{
+	"asshole", "bastard", "bitch", "bullshit", "cunt", "dick", "fuck", "fucker", "fucking",
+	"motherfucker", "nigger", "shit", "slut", "whore",
+	"anjing", "bangsat", "bajingan", "goblok", "kontol", "memek", "ngentot", "tolol",
+}
+
+var (
+	blockedWords   = newWordSet(defaultBlockedWords)
+	blocklistMutex sync.RWMutex
+)
+
+func newWordSet(words []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+// InitBlocklist adds extra words to the built-in list used to moderate donor messages
+func InitBlocklist(extra []string) {
+	blocklistMutex.Lock()
+	defer blocklistMutex.Unlock()
+
+	blockedWords = newWordSet(append(append([]string{}, defaultBlockedWords...), extra...))
+}
+
+// ContainsBlockedWord reports whether any word in text is on the blocklist.
+// Common digit substitutions such as "sh1t" are undone before matching.
+func ContainsBlockedWord(text string) bool {
+	blocklistMutex.RLock()
+	defer blocklistMutex.RUnlock()
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '$'
+	})
+	for _, word := range words {
+		if blockedWords[word] || blockedWords[leetReplacer.Replace(word)] {
+			return true
+		}
+	}
+	return false
+}
+
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
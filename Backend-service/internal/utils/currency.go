@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	defaultCurrency     = "USD"
+	supportedCurrencies = map[string]bool{"IDR": true, "USD": true, "EUR": true}
+	currencyMutex       sync.RWMutex
+)
+
+// InitCurrencies sets the default currency and the ISO 4217 codes accepted for campaigns and donations
+func InitCurrencies(defaultCode string, supported []string) {
+	currencyMutex.Lock()
+	defer currencyMutex.Unlock()
+
+	supportedCurrencies = make(map[string]bool)
+	for _, code := range supported {
+		if code = NormalizeCurrency(code); code != "" {
+			supportedCurrencies[code] = true
+		}
+	}
+
+	defaultCurrency = NormalizeCurrency(defaultCode)
+	supportedCurrencies[defaultCurrency] = true
+}
+
+func DefaultCurrency() string {
+	currencyMutex.RLock()
+	defer currencyMutex.RUnlock()
+	return defaultCurrency
+}
+
+func IsSupportedCurrency(code string) bool {
+	currencyMutex.RLock()
+	defer currencyMutex.RUnlock()
+	return supportedCurrencies[code]
+}
+
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// SignHMAC returns the hex encoded HMAC-SHA256 of payload
+func SignHMAC(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyHMAC compares signature with the HMAC-SHA256 of payload in constant time
+func VerifyHMAC(secret string, payload []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(SignHMAC(secret, payload)), []byte(signature))
+}
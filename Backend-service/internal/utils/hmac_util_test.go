@@ -0,0 +1,32 @@
+package utils
+
+import "testing"
+
+func TestVerifyHMAC(t *testing.T) {
+	const secret = "webhook-secret"
+	payload := []byte(`{"event_id":"evt-1","transaction_id":"fake-1","status":"paid"}`)
+	signature := SignHMAC(secret, payload)
+
+	tests := []struct {
+		name      string
+		secret    string
+		payload   []byte
+		signature string
+		want      bool
+	}{
+		{"good signature", secret, payload, signature, true},
+		{"tampered body", secret, []byte(`{"event_id":"evt-1","transaction_id":"fake-1","status":"refunded"}`), signature, false},
+		{"wrong secret", "another-secret", payload, signature, false},
+		{"empty secret", "", payload, SignHMAC("", payload), false},
+		{"empty signature", secret, payload, "", false},
+		{"truncated signature", secret, payload, signature[:len(signature)-2], false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyHMAC(tt.secret, tt.payload, tt.signature); got != tt.want {
+				t.Errorf("VerifyHMAC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -27,15 +27,17 @@ func GetJWTUtil() *JWTUtil {
 }
 
 type JWTUtilInterface interface {
-	GenerateJWT(username string, id int64, role string) (string, error)
+	GenerateJWT(username string, id int64, role string, sessionID int64, ttl time.Duration) (string, error)
 }
 
-func (j *JWTUtil) GenerateJWT(username string, id int64, role string) (string, error) {
+// GenerateJWT issues an access token bound to the session it was refreshed from
+func (j *JWTUtil) GenerateJWT(username string, id int64, role string, sessionID int64, ttl time.Duration) (string, error) {
 	claims := jwt.MapClaims{
 		"username": username,
 		"id":       id,
 		"role":     role,
-		"exp":      time.Now().Add(24 * time.Hour).Unix(),
+		"sid":      sessionID,
+		"exp":      time.Now().Add(ttl).Unix(),
 		"iat":      time.Now().Unix(),
 	}
 
@@ -96,19 +98,3 @@ func (j *JWTUtil) VerifyToken(tokenStr string) (jwt.MapClaims, error) {
 	return nil, errors.New("invalid token")
 
 }
-
-
-
-// Generate reset password token (shorter expiry)
-func (j *JWTUtil) GenerateResetToken(userID int64, email string) (string, error) {
-	claims := jwt.MapClaims{
-		"id":    userID,
-		"email": email,
-		"type":  "reset_password",
-		"exp":   time.Now().Add(1 * time.Hour).Unix(), // 1 hour expiry
-		"iat":   time.Now().Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secret)
-}
\ No newline at end of file
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and margins in PDF points
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 56.0
+)
+
+type pdfLine struct {
+	text string
+	size float64
+	bold bool
+	y    float64
+}
+
+// PDFDocument builds a plain text PDF using the standard Helvetica fonts, so
+// no font files need to be embedded. Text outside Latin-1 is replaced by '?'.
+type PDFDocument struct {
+	pages [][]pdfLine
+	y     float64
+}
+
+func NewPDFDocument() *PDFDocument {
+	d := &PDFDocument{}
+	d.newPage()
+	return d
+}
+
+// RenderTextPDF lays out text line by line. Lines starting with "# " are
+// rendered as a title and lines starting with "## " as a section heading.
+func RenderTextPDF(text string) []byte {
+	d := NewPDFDocument()
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "# "):
+			d.AddLine(strings.TrimPrefix(line, "# "), 18, true)
+		case strings.HasPrefix(line, "## "):
+			d.AddLine(strings.TrimPrefix(line, "## "), 12, true)
+		default:
+			d.AddLine(line, 10, false)
+		}
+	}
+	return d.Bytes()
+}
+
+func (d *PDFDocument) newPage() {
+	d.pages = append(d.pages, nil)
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// AddLine appends a line of text, wrapping it to the page width and starting
+// a new page when the current one is full
+func (d *PDFDocument) AddLine(text string, size float64, bold bool) {
+	// Helvetica glyphs average about half the font size in width
+	maxChars := int((pdfPageWidth - 2*pdfMargin) / (size * 0.5))
+	for _, part := range wrapText(text, maxChars) {
+		lineHeight := size * 1.4
+		if d.y-lineHeight < pdfMargin {
+			d.newPage()
+		}
+		d.y -= lineHeight
+		page := len(d.pages) - 1
+		d.pages[page] = append(d.pages[page], pdfLine{text: part, size: size, bold: bold, y: d.y})
+	}
+}
+
+func wrapText(text string, maxChars int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range words {
+		switch {
+		case current == "":
+			current = word
+		case len(current)+1+len(word) > maxChars:
+			lines = append(lines, current)
+			current = word
+		default:
+			current += " " + word
+		}
+	}
+	return append(lines, current)
+}
+
+// Bytes serializes the document
+func (d *PDFDocument) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	writeObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-4 are the catalog, the page tree and the two fonts;
+	// each page then takes a page object followed by its content stream
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		writeObject(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i,
+		))
+
+		var content bytes.Buffer
+		for _, line := range page {
+			font := "F1"
+			if line.bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n", font, line.size, pdfMargin, line.y, pdfEscape(line.text))
+		}
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// pdfEscape encodes text as WinAnsi and escapes PDF string delimiters
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -16,6 +16,3 @@ CREATE TABLE IF NOT EXISTS users (
 );
 
 CREATE INDEX idx_users_email ON users(email);
-
-INSERT INTO users (name, email, password, role) 
-VALUES ('Admin', 'admin@sharethemeal.org', '$2a$10$1qAz2wSx3eDc4rFv5tGb5t/yMB3Vjr97gYVZcROVqlfS1QgURXOa.', 1);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_donations_transaction_id;
+DROP TABLE IF EXISTS payment_webhook_events CASCADE;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    transaction_id VARCHAR(255),
+    status VARCHAR(20),
+    payload JSONB NOT NULL,
+    signature VARCHAR(255),
+    processed BOOLEAN NOT NULL DEFAULT FALSE,
+    processing_error TEXT,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(provider, event_id)
+);
+
+CREATE INDEX idx_payment_webhook_events_transaction_id ON payment_webhook_events(transaction_id);
+CREATE INDEX idx_donations_transaction_id ON donations(transaction_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS exchange_rates CASCADE;
+ALTER TABLE donations DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE donations DROP COLUMN IF EXISTS original_amount;
+ALTER TABLE donations DROP COLUMN IF EXISTS currency;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,23 @@
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- amount stays in the campaign currency, original_amount is what the donor paid
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS original_amount DECIMAL(12, 2);
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(20, 10);
+
+UPDATE donations SET original_amount = amount, exchange_rate = 1 WHERE original_amount IS NULL;
+
+ALTER TABLE donations ALTER COLUMN original_amount SET NOT NULL;
+ALTER TABLE donations ALTER COLUMN exchange_rate SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id SERIAL PRIMARY KEY,
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    created_by VARCHAR(255),
+    modified_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    modified_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(base_currency, quote_currency)
+);
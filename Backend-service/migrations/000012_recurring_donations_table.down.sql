@@ -0,0 +1,2 @@
+ALTER TABLE donations DROP COLUMN IF EXISTS recurring_donation_id;
+DROP TABLE IF EXISTS recurring_donations CASCADE;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS recurring_donations (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(user_id) NOT NULL,
+    campaign_id INTEGER NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    is_anonymous BOOLEAN DEFAULT FALSE,
+    payment_method VARCHAR(50),
+    interval VARCHAR(20) NOT NULL DEFAULT 'monthly',
+    anchor_day INTEGER NOT NULL CHECK (anchor_day BETWEEN 1 AND 31),
+    next_charge_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_charged_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_by VARCHAR(255),
+    modified_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    modified_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_recurring_donations_user_id ON recurring_donations(user_id);
+CREATE INDEX idx_recurring_donations_due ON recurring_donations(status, next_charge_date);
+
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS recurring_donation_id INTEGER REFERENCES recurring_donations(id);
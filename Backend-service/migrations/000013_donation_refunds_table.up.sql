@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS donation_refunds (
+    id SERIAL PRIMARY KEY,
+    donation_id INTEGER REFERENCES donations(id) NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount >= 0),
+    original_amount DECIMAL(12, 2) NOT NULL CHECK (original_amount > 0),
+    currency CHAR(3) NOT NULL,
+    reason TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'completed' CHECK (status IN ('pending', 'completed', 'failed')),
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_donation_refunds_donation_id ON donation_refunds(donation_id);
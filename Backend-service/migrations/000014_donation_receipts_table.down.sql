@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS donation_receipts CASCADE;
+DROP TABLE IF EXISTS receipt_sequences CASCADE;
@@ -0,0 +1,16 @@
+-- One counter row per year; incrementing it inside the issuing transaction
+-- keeps receipt numbers gap-free because a rollback also undoes the increment
+CREATE TABLE IF NOT EXISTS receipt_sequences (
+    receipt_year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS donation_receipts (
+    id SERIAL PRIMARY KEY,
+    donation_id INTEGER REFERENCES donations(id) NOT NULL UNIQUE,
+    receipt_year INTEGER NOT NULL,
+    sequence_number INTEGER NOT NULL,
+    receipt_number VARCHAR(20) NOT NULL UNIQUE,
+    issued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(receipt_year, sequence_number)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_donations_wall_of_support;
+ALTER TABLE donations DROP COLUMN IF EXISTS message_status;
+ALTER TABLE donations DROP COLUMN IF EXISTS dedication_name;
+ALTER TABLE donations DROP COLUMN IF EXISTS dedication_type;
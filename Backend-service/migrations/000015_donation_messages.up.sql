@@ -0,0 +1,8 @@
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS message TEXT;
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS dedication_type VARCHAR(20)
+    CHECK (dedication_type IN ('honor', 'memory'));
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS dedication_name VARCHAR(255);
+ALTER TABLE donations ADD COLUMN IF NOT EXISTS message_status VARCHAR(20);
+
+CREATE INDEX idx_donations_wall_of_support ON donations(campaign_id, created_at DESC)
+    WHERE message_status = 'approved';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_donation_refunds_donation_amount;
+DROP INDEX IF EXISTS idx_donations_campaign_paid;
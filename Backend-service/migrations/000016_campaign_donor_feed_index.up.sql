@@ -0,0 +1,5 @@
+-- Serves the public donor feed and leaderboard, which only read paid donations
+CREATE INDEX IF NOT EXISTS idx_donations_campaign_paid ON donations(campaign_id, created_at DESC)
+    WHERE payment_status = 'paid';
+
+CREATE INDEX IF NOT EXISTS idx_donation_refunds_donation_amount ON donation_refunds(donation_id) INCLUDE (amount);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_created_at;
+DROP INDEX IF EXISTS idx_donations_paid_created_at;
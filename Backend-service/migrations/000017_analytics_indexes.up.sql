@@ -0,0 +1,4 @@
+-- Date-range scans for the CMS analytics endpoints
+CREATE INDEX IF NOT EXISTS idx_donations_paid_created_at ON donations(created_at)
+    WHERE payment_status = 'paid';
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
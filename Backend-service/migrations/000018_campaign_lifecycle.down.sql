@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_campaigns_open_end;
+DROP INDEX IF EXISTS idx_campaigns_scheduled_start;
+
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS chk_campaigns_dates;
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS chk_campaigns_status;
+ALTER TABLE campaigns ALTER COLUMN status SET DEFAULT 'active';
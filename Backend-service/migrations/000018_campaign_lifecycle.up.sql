@@ -0,0 +1,14 @@
+-- Campaigns move draft -> scheduled -> active -> completed/expired -> archived
+UPDATE campaigns SET status = 'active'
+    WHERE status NOT IN ('draft', 'scheduled', 'active', 'completed', 'expired', 'archived');
+
+ALTER TABLE campaigns ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE campaigns ADD CONSTRAINT chk_campaigns_status
+    CHECK (status IN ('draft', 'scheduled', 'active', 'completed', 'expired', 'archived'));
+ALTER TABLE campaigns ADD CONSTRAINT chk_campaigns_dates CHECK (end_date > start_date);
+
+-- Lets the lifecycle job find campaigns to open and close without a full scan
+CREATE INDEX IF NOT EXISTS idx_campaigns_scheduled_start ON campaigns(start_date)
+    WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_campaigns_open_end ON campaigns(end_date)
+    WHERE status IN ('scheduled', 'active');
@@ -0,0 +1,15 @@
+ALTER TABLE recurring_donations DROP CONSTRAINT IF EXISTS fk_recurring_donations_campaign_id;
+
+ALTER TABLE notifications ALTER COLUMN type DROP DEFAULT;
+
+ALTER TABLE users ALTER COLUMN is_active DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN is_active DROP DEFAULT;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role_id;
+ALTER TABLE users ALTER COLUMN role_id DROP NOT NULL;
+ALTER TABLE users RENAME COLUMN role_id TO role;
+
+ALTER TABLE donations ALTER COLUMN is_anonymous DROP NOT NULL;
+ALTER TABLE donations RENAME COLUMN is_anonymous TO anonymous;
+ALTER INDEX idx_donations_user_id RENAME TO idx_donations_donor_id;
+ALTER TABLE donations RENAME COLUMN user_id TO donor_id;
@@ -0,0 +1,28 @@
+-- Align column names with the ones the repositories use
+
+-- donations: donor_id -> user_id, anonymous -> is_anonymous
+ALTER TABLE donations RENAME COLUMN donor_id TO user_id;
+ALTER INDEX idx_donations_donor_id RENAME TO idx_donations_user_id;
+ALTER TABLE donations RENAME COLUMN anonymous TO is_anonymous;
+UPDATE donations SET is_anonymous = FALSE WHERE is_anonymous IS NULL;
+ALTER TABLE donations ALTER COLUMN is_anonymous SET NOT NULL;
+
+-- users: role -> role_id, now referencing roles
+ALTER TABLE users RENAME COLUMN role TO role_id;
+UPDATE users SET role_id = (SELECT role_id FROM roles WHERE role_name = 'donor') WHERE role_id IS NULL;
+ALTER TABLE users ALTER COLUMN role_id SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT fk_users_role_id FOREIGN KEY (role_id) REFERENCES roles(role_id);
+
+-- Lookups filter on is_active = true, so NULL would hide the user
+UPDATE users SET is_active = TRUE WHERE is_active IS NULL;
+ALTER TABLE users ALTER COLUMN is_active SET DEFAULT TRUE;
+ALTER TABLE users ALTER COLUMN is_active SET NOT NULL;
+
+-- The default roles were inserted with explicit ids, move the sequence past them
+SELECT setval(pg_get_serial_sequence('roles', 'role_id'), (SELECT MAX(role_id) FROM roles));
+
+-- Notifications are created without a type
+ALTER TABLE notifications ALTER COLUMN type SET DEFAULT 'general';
+
+ALTER TABLE recurring_donations ADD CONSTRAINT fk_recurring_donations_campaign_id
+    FOREIGN KEY (campaign_id) REFERENCES campaigns(campaign_id);
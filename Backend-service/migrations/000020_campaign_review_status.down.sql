@@ -0,0 +1,5 @@
+UPDATE campaigns SET status = 'draft' WHERE status = 'pending_review';
+
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS chk_campaigns_status;
+ALTER TABLE campaigns ADD CONSTRAINT chk_campaigns_status
+    CHECK (status IN ('draft', 'scheduled', 'active', 'completed', 'expired', 'archived'));
@@ -0,0 +1,4 @@
+-- Recipients submit draft campaigns for superadmin review
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS chk_campaigns_status;
+ALTER TABLE campaigns ADD CONSTRAINT chk_campaigns_status
+    CHECK (status IN ('draft', 'pending_review', 'scheduled', 'active', 'completed', 'expired', 'archived'));
@@ -0,0 +1,16 @@
+-- Recipient and superadmin accounts are only created through invitations
+CREATE TABLE IF NOT EXISTS invitations (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    role_id INTEGER REFERENCES roles(role_id) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    accepted_user_id INTEGER REFERENCES users(user_id),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by VARCHAR(255),
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_invitations_email ON invitations(LOWER(email)) WHERE accepted_at IS NULL AND revoked_at IS NULL;
+CREATE INDEX idx_invitations_created_at ON invitations(created_at DESC);